@@ -1,46 +1,131 @@
 package analyzer
 
 import (
+	"time"
+
+	"goqkview/catalog"
 	"goqkview/interfaces"
 	"goqkview/parser"
+	"goqkview/rules"
 )
 
 type Analyzer struct {
 	sslAnalyzer     *SSLAnalyzer
 	errorAnalyzer   *ErrorAnalyzer
 	timelineBuilder *TimelineBuilder
+	changeAnalyzer  *ChangeAnalyzer
+	incidents       *IncidentDetector
 	recommender     *Recommender
 	vsAnalyzer      *VirtualServerAnalyzer
+	monitorAnalyzer *MonitorAnalyzer
+	nodeAnalyzer    *NodeAnalyzer
+	iruleAnalyzer   *IRuleAnalyzer
+	hygieneAnalyzer *HygieneAnalyzer
+	ruleAnalyzer    *RuleAnalyzer
 }
 
-func New() *Analyzer {
+// New creates an analyzer that classifies message IDs with messages; nil
+// means the built-in catalog.
+func New(messages *catalog.Catalog) *Analyzer {
+	if messages == nil {
+		messages = catalog.Default()
+	}
+	errorAnalyzer := NewErrorAnalyzer(messages)
 	return &Analyzer{
 		sslAnalyzer:     NewSSLAnalyzer(),
-		errorAnalyzer:   NewErrorAnalyzer(),
-		timelineBuilder: NewTimelineBuilder(),
+		errorAnalyzer:   errorAnalyzer,
+		timelineBuilder: NewTimelineBuilder(errorAnalyzer),
+		changeAnalyzer:  NewChangeAnalyzer(),
+		incidents:       NewIncidentDetector(errorAnalyzer),
 		recommender:     NewRecommender(),
 		vsAnalyzer:      NewVirtualServerAnalyzer(),
+		monitorAnalyzer: NewMonitorAnalyzer(),
+		nodeAnalyzer:    NewNodeAnalyzer(),
+		iruleAnalyzer:   NewIRuleAnalyzer(),
+		hygieneAnalyzer: NewHygieneAnalyzer(),
+		ruleAnalyzer:    NewRuleAnalyzer(),
 	}
 }
 
-func (a *Analyzer) Analyze(entries []interfaces.LogEntry, bigipConfig *parser.BigIPConfig) (*AnalysisResult, error) {
+// SetTimelineResolution sets the error timeline bucket size: minute, hour,
+// day or auto (the default).
+func (a *Analyzer) SetTimelineResolution(resolution string) error {
+	return a.timelineBuilder.SetResolution(resolution)
+}
+
+// SetRules sets the user-defined rules evaluated with the built-in checks.
+func (a *Analyzer) SetRules(set *rules.RuleSet) {
+	a.ruleAnalyzer.SetRules(set)
+}
+
+// SetTopErrors sets how many error groups are reported; 0 means all.
+func (a *Analyzer) SetTopErrors(n int) {
+	a.errorAnalyzer.SetTopN(n)
+}
+
+func (a *Analyzer) Analyze(entries []interfaces.LogEntry, bigipConfig *parser.BigIPConfig, device *parser.DeviceInfo) (*AnalysisResult, error) {
 	result := &AnalysisResult{}
 
-	result.ErrorTimeline = a.timelineBuilder.Build(entries)
-	result.SSLFindings = a.sslAnalyzer.Analyze(entries)
+	result.Device = buildDevice(device)
+
+	reference, source := referenceTime(entries, device)
+	result.ReferenceTime = reference.UTC().Format("2006-01-02T15:04:05Z")
+	result.ReferenceTimeSource = source
+
+	// Error groups are named by their templates from here on.
+	a.errorAnalyzer.Learn(entries)
+
+	result.ConfigChanges = a.changeAnalyzer.Analyze(entries)
+	result.ErrorTimeline, result.TimelineResolution = a.timelineBuilder.Build(entries, result.ConfigChanges)
+	result.Incidents = a.incidents.Detect(result.ErrorTimeline, result.TimelineResolution, entries, bigipConfig)
+	version := ""
+	if device != nil {
+		version = device.Version
+	}
+	result.SSLFindings = a.sslAnalyzer.Analyze(entries, bigipConfig, reference, version)
 	result.TopErrors = a.errorAnalyzer.Analyze(entries)
-	result.VirtualServers = a.vsAnalyzer.Analyze(bigipConfig, entries)
+	result.MemberHistory = a.monitorAnalyzer.Analyze(entries, reference)
+	result.VirtualServers = a.vsAnalyzer.Analyze(bigipConfig, entries, result.MemberHistory)
+	result.Nodes = a.nodeAnalyzer.Analyze(bigipConfig, entries, result.VirtualServers)
+	result.IRules = a.iruleAnalyzer.Analyze(bigipConfig, entries)
+	result.ConfigHygiene = a.hygieneAnalyzer.Analyze(bigipConfig)
+	result.RuleFindings = a.ruleAnalyzer.Analyze(entries, bigipConfig)
+	a.ruleAnalyzer.mergeRuleFindings(result.RuleFindings, result.VirtualServers)
 	result.Summary = a.buildSummary(result.VirtualServers, result.SSLFindings)
 	result.EntryLogs = a.convertToEntryLogs(entries)
 	result.Recommendations = a.recommender.Generate(
 		result.Summary,
 		result.SSLFindings,
 		result.TopErrors,
+		result.Nodes,
+		result.IRules,
+		result.ConfigChanges,
+		result.Incidents,
+		result.RuleFindings,
 	)
 
 	return result, nil
 }
 
+// referenceTime is the moment expiry and outage windows are measured
+// against: the qkview capture time, else the newest log entry, else now.
+func referenceTime(entries []interfaces.LogEntry, device *parser.DeviceInfo) (time.Time, string) {
+	if device != nil && !device.GeneratedAt.IsZero() {
+		return device.GeneratedAt, "qkview"
+	}
+
+	var newest time.Time
+	for _, e := range entries {
+		if e.Timestamp.After(newest) {
+			newest = e.Timestamp
+		}
+	}
+	if !newest.IsZero() {
+		return newest, "logs"
+	}
+	return time.Now(), "now"
+}
+
 func (a *Analyzer) buildSummary(virtualServers []VirtualServerInfo, sslFindings []SSLFinding) Summary {
 	summary := Summary{}
 
@@ -73,10 +158,36 @@ func (a *Analyzer) convertToEntryLogs(entries []interfaces.LogEntry) []EntryLog
 			date = e.Timestamp.Format("2006-01-02T15:04:05Z")
 		}
 		logs[i] = EntryLog{
-			Message: e.Line,
-			Level:   e.Status,
-			Date:    date,
+			Message:    e.Line,
+			Body:       e.Body,
+			Level:      e.Status,
+			LevelFrom:  e.SeverityMethod,
+			Date:       date,
+			UTCOffset:  e.UTCOffset,
+			OutOfOrder: e.OutOfOrder,
+		}
+		for _, ref := range e.Objects {
+			logs[i].Objects = appendUnique(logs[i].Objects, ref.Path)
 		}
 	}
 	return logs
 }
+
+// isErrorStatus is true for syslog err and above, and restjavad's SEVERE.
+func isErrorStatus(status string) bool {
+	switch status {
+	case "EMERGENCY", "ALERT", "CRITICAL", "ERROR", "SEVERE":
+		return true
+	}
+	return false
+}
+
+// messageText is what analyzers match against: the syslog message without
+// the timestamp, host and process header when the line was parsed, the raw
+// line otherwise.
+func messageText(entry interfaces.LogEntry) string {
+	if entry.Message != "" {
+		return entry.Message
+	}
+	return entry.Line
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"goqkview/interfaces"
+)
+
+// Audit records of one user and interface at most mergeWindow apart are one
+// change.
+const mergeWindow = time.Second
+
+// A change is followed by an error spike when at least spikeMinErrors error
+// and warning lines fall in the changeWindow after it and that is spikeFactor
+// times as many as in the window before.
+const (
+	changeWindow   = time.Hour
+	spikeMinErrors = 5
+	spikeFactor    = 3
+)
+
+type ChangeAnalyzer struct{}
+
+func NewChangeAnalyzer() *ChangeAnalyzer {
+	return &ChangeAnalyzer{}
+}
+
+// Analyze builds the configuration change timeline from audit records,
+// oldest first. A record by the same user through the same interface within
+// mergeWindow of that user's previous record is part of the same change:
+// tmsh logs the command and mcpd each transaction it commits, which may fall
+// on either side of a second boundary.
+func (c *ChangeAnalyzer) Analyze(entries []interfaces.LogEntry) []ConfigChange {
+	var audit []interfaces.LogEntry
+	var errorTimes []time.Time
+	for _, entry := range entries {
+		if entry.Timestamp.IsZero() {
+			continue
+		}
+		if isTimelineStatus(entry.Status) {
+			errorTimes = append(errorTimes, entry.Timestamp)
+		}
+		if entry.Audit != nil {
+			audit = append(audit, entry)
+		}
+	}
+	sort.SliceStable(audit, func(i, j int) bool {
+		return audit[i].Timestamp.Before(audit[j].Timestamp)
+	})
+	sort.Slice(errorTimes, func(i, j int) bool {
+		return errorTimes[i].Before(errorTimes[j])
+	})
+
+	changes := []ConfigChange{}
+	lastLogin := make(map[string]string) // user -> address of the latest successful login
+	type open struct {
+		index int       // Into changes
+		last  time.Time // Latest record merged into it
+	}
+	previous := make(map[string]*open) // user|interface -> their latest change
+
+	for _, entry := range audit {
+		record := entry.Audit
+		if record.Action == "login" {
+			if record.Success && record.Address != "" {
+				lastLogin[record.User] = record.Address
+			}
+			continue
+		}
+		if !record.Change {
+			continue
+		}
+
+		key := record.User + "|" + record.Interface
+		if p, ok := previous[key]; ok && entry.Timestamp.Sub(p.last) <= mergeWindow {
+			c.merge(&changes[p.index], record)
+			p.last = entry.Timestamp
+			continue
+		}
+
+		change := ConfigChange{
+			Time:      entry.Timestamp.Format("2006-01-02T15:04:05Z"),
+			User:      record.User,
+			Address:   record.Address,
+			Interface: record.Interface,
+			Action:    record.Action,
+			Objects:   []string{},
+			Command:   record.Command,
+			Result:    record.Result,
+			Success:   record.Success,
+			time:      entry.Timestamp,
+		}
+		if change.Address == "" {
+			change.Address = lastLogin[record.User]
+		}
+		if record.Object != "" {
+			change.Objects = append(change.Objects, record.Object)
+		}
+		previous[key] = &open{index: len(changes), last: entry.Timestamp}
+		changes = append(changes, change)
+	}
+
+	for i := range changes {
+		c.correlate(&changes[i], errorTimes)
+	}
+	return changes
+}
+
+// merge folds a further record of the same change in; a failed record makes
+// the whole change failed.
+func (c *ChangeAnalyzer) merge(change *ConfigChange, record *interfaces.AuditRecord) {
+	if record.Object != "" && !slices.Contains(change.Objects, record.Object) {
+		change.Objects = append(change.Objects, record.Object)
+	}
+	if change.Success && !record.Success {
+		change.Success = false
+		change.Result = record.Result
+	}
+}
+
+// correlate counts error and warning lines around the change. errorTimes
+// must be sorted.
+func (c *ChangeAnalyzer) correlate(change *ConfigChange, errorTimes []time.Time) {
+	at := func(t time.Time) int {
+		return sort.Search(len(errorTimes), func(i int) bool { return !errorTimes[i].Before(t) })
+	}
+	start, mid, end := at(change.time.Add(-changeWindow)), at(change.time), at(change.time.Add(changeWindow))
+
+	change.ErrorsBefore = mid - start
+	change.ErrorsAfter = end - mid
+	change.ErrorSpike = change.ErrorsAfter >= spikeMinErrors && change.ErrorsAfter >= spikeFactor*change.ErrorsBefore
+}
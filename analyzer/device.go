@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"goqkview/parser"
+)
+
+func buildDevice(info *parser.DeviceInfo) *Device {
+	if info == nil {
+		return nil
+	}
+
+	device := &Device{
+		Hostname:      info.Hostname,
+		Product:       info.Product,
+		Version:       info.Version,
+		Build:         info.Build,
+		Edition:       info.Edition,
+		Hotfix:        info.Hotfix,
+		PlatformID:    info.PlatformID,
+		MarketingName: info.MarketingName,
+		Serial:        info.Serial,
+		ManagementIP:  info.ManagementIP,
+		TimeZone:      info.TimeZone,
+		Modules:       make([]string, 0, len(info.Modules)),
+		HARole:        info.HARole,
+		FailoverState: info.FailoverState,
+		SyncStatus:    info.SyncStatus,
+		UptimeSeconds: int64(info.Uptime.Seconds()),
+	}
+	for _, m := range info.Modules {
+		device.Modules = append(device.Modules, fmt.Sprintf("%s (%s)", m.Name, m.Level))
+	}
+	if !info.GeneratedAt.IsZero() {
+		device.GeneratedAt = info.GeneratedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return device
+}
@@ -1,84 +1,239 @@
 package analyzer
 
 import (
-	"regexp"
+	"math"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"goqkview/catalog"
 	"goqkview/interfaces"
 )
 
+// defaultTopErrors is how many error groups are reported unless SetTopN says otherwise.
+const defaultTopErrors = 10
+
+// Sample parameter lists and raw lines kept per error group.
+const (
+	sampleParams = 3
+	sampleLines  = 3
+)
+
 type ErrorAnalyzer struct {
-	ipPattern   *regexp.Regexp
-	portPattern *regexp.Regexp
+	messages  *catalog.Catalog
+	templates *templateMiner
+	topN      int
 }
 
-func NewErrorAnalyzer() *ErrorAnalyzer {
+func NewErrorAnalyzer(messages *catalog.Catalog) *ErrorAnalyzer {
 	return &ErrorAnalyzer{
-		ipPattern:   regexp.MustCompile(`\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}`),
-		portPattern: regexp.MustCompile(`:\d{2,5}\b`),
+		messages:  messages,
+		templates: newTemplateMiner(),
+		topN:      defaultTopErrors,
+	}
+}
+
+// SetTopN sets how many error groups Analyze returns; 0 or less means all.
+func (e *ErrorAnalyzer) SetTopN(n int) {
+	e.topN = n
+}
+
+// Learn mines message templates from the error and warning entries, so that
+// every later lookup sees the final templates whatever the entry order.
+func (e *ErrorAnalyzer) Learn(entries []interfaces.LogEntry) {
+	e.templates = newTemplateMiner()
+	for _, entry := range entries {
+		if isTimelineStatus(entry.Status) {
+			e.templates.add(tokenize(messageText(entry)).masked)
+		}
 	}
 }
 
+// Analyze groups the error entries and reports the largest groups with the
+// files, hosts and configuration objects of their lines.
 func (e *ErrorAnalyzer) Analyze(entries []interfaces.LogEntry) []TopError {
 	groups := make(map[string]*errorGroup)
 
 	for _, entry := range entries {
-		if entry.Status != "ERROR" && entry.Status != "CRITICAL" && entry.Status != "SEVERE" {
+		if !isErrorStatus(entry.Status) {
 			continue
 		}
 
-		normalized := e.normalizeMessage(entry.Line)
+		message := messageText(entry)
+		tokens := tokenize(message)
+		cluster := e.templates.match(tokens.masked)
+		key := groupKey(entry, cluster)
 
-		if group, exists := groups[normalized]; exists {
-			group.count++
-			if entry.Timestamp.After(group.lastOccurred) {
-				group.lastOccurred = entry.Timestamp
-				group.representative = entry.Line
-			}
-		} else {
-			groups[normalized] = &errorGroup{
-				normalized:     normalized,
-				representative: entry.Line,
-				count:          1,
-				lastOccurred:   entry.Timestamp,
+		group, exists := groups[key]
+		if !exists {
+			group = &errorGroup{
+				messageID: entry.MessageID,
+				templates: make(map[string]int),
 			}
+			groups[key] = group
+		}
+		group.count++
+		group.templates[cluster.template()]++
+		if group.representative == "" || entry.Timestamp.After(group.lastOccurred) {
+			group.lastOccurred = entry.Timestamp
+			group.representative = message
 		}
+		if group.firstOccurred.IsZero() || (!entry.Timestamp.IsZero() && entry.Timestamp.Before(group.firstOccurred)) {
+			group.firstOccurred = entry.Timestamp
+		}
+		if params := cluster.params(tokens); len(params) > 0 && len(group.params) < sampleParams &&
+			!slices.ContainsFunc(group.params, func(p []string) bool { return slices.Equal(p, params) }) {
+			group.params = append(group.params, params)
+		}
+
+		group.files = appendUnique(group.files, logFile(entry.Path))
+		host := entry.Host
+		if host == "" {
+			host = entry.Hostname
+		}
+		if host != "" {
+			group.hosts = appendUnique(group.hosts, host)
+		}
+		mentioned := mentions(entry)
+		for _, vs := range mentioned.virtualServers {
+			group.virtualServers = appendUnique(group.virtualServers, vs)
+		}
+		for _, pool := range mentioned.pools {
+			group.pools = appendUnique(group.pools, pool)
+		}
+		for _, member := range mentioned.members {
+			group.members = appendUnique(group.members, member)
+		}
+		group.addSample(entry)
 	}
 
 	result := make([]TopError, 0, len(groups))
 	for _, group := range groups {
-		result = append(result, TopError{
+		topError := TopError{
 			Message:      e.extractErrorMessage(group.representative),
 			Count:        group.count,
 			LastOccurred: group.lastOccurred,
-		})
+			Template:     truncate(group.template(), 200),
+			SampleParams: group.params,
+			MessageID:    group.messageID,
+
+			FirstOccurred:  group.firstOccurred,
+			RatePerHour:    group.ratePerHour(),
+			Files:          sorted(group.files),
+			Hosts:          sorted(group.hosts),
+			VirtualServers: sorted(group.virtualServers),
+			Pools:          sorted(group.pools),
+			Members:        sorted(group.members),
+			Samples:        group.samples,
+		}
+		if m, ok := e.messages.Lookup(group.messageID); ok {
+			topError.Component = m.Component
+			topError.Meaning = m.Meaning
+			topError.Severity = m.Severity
+			topError.Action = m.Action
+		}
+		result = append(result, topError)
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Count > result[j].Count
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Template < result[j].Template
 	})
 
-	if len(result) > 10 {
-		result = result[:10]
+	if e.topN > 0 && len(result) > e.topN {
+		result = result[:e.topN]
 	}
 
 	return result
 }
 
 type errorGroup struct {
-	normalized     string
+	messageID      string
 	representative string
 	count          int
+	firstOccurred  time.Time
 	lastOccurred   time.Time
+	templates      map[string]int // Templates of the group's lines
+	params         [][]string
+
+	files          []string
+	hosts          []string
+	virtualServers []string
+	pools          []string
+	members        []string
+	samples        []ErrorSample // Earliest lines, oldest first
+	sampleTimes    []time.Time
+}
+
+// addSample keeps the earliest sampleLines lines of the group.
+func (g *errorGroup) addSample(entry interfaces.LogEntry) {
+	i := sort.Search(len(g.sampleTimes), func(i int) bool {
+		return g.sampleTimes[i].After(entry.Timestamp)
+	})
+	if i >= sampleLines {
+		return
+	}
+	g.samples = slices.Insert(g.samples, i, ErrorSample{
+		File:       logFile(entry.Path),
+		LineNumber: entry.LineNumber,
+		Line:       truncate(entry.Line, 500),
+	})
+	g.sampleTimes = slices.Insert(g.sampleTimes, i, entry.Timestamp)
+	if len(g.samples) > sampleLines {
+		g.samples = g.samples[:sampleLines]
+		g.sampleTimes = g.sampleTimes[:sampleLines]
+	}
+}
+
+// ratePerHour spreads the occurrences over the time between the first and
+// last one, counting at least an hour.
+func (g *errorGroup) ratePerHour() float64 {
+	hours := g.lastOccurred.Sub(g.firstOccurred).Hours()
+	if hours < 1 {
+		hours = 1
+	}
+	return math.Round(float64(g.count)/hours*100) / 100
 }
 
-func (e *ErrorAnalyzer) normalizeMessage(line string) string {
-	normalized := e.ipPattern.ReplaceAllString(line, "X.X.X.X")
-	normalized = e.portPattern.ReplaceAllString(normalized, ":XXXX")
-	normalized = strings.Join(strings.Fields(normalized), " ")
-	return strings.ToLower(normalized)
+func sorted(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// template is the template most of the group's lines follow.
+func (g *errorGroup) template() string {
+	best := ""
+	for template, n := range g.templates {
+		if best == "" || n > g.templates[best] || (n == g.templates[best] && template < best) {
+			best = template
+		}
+	}
+	return best
+}
+
+// groupKey says which error group an entry belongs to. Lines with a message
+// ID are the same condition whatever their text; other lines are grouped by
+// the template of their cluster.
+func groupKey(entry interfaces.LogEntry, cluster *templateCluster) string {
+	if entry.MessageID != "" {
+		return "id:" + entry.MessageID
+	}
+	return cluster.template()
+}
+
+// groupLabel names an entry's error group for output: the message ID, else
+// the template.
+func (e *ErrorAnalyzer) groupLabel(entry interfaces.LogEntry) string {
+	if entry.MessageID != "" {
+		return entry.MessageID
+	}
+	return truncate(e.templates.match(tokenize(messageText(entry)).masked).template(), 100)
 }
 
 func (e *ErrorAnalyzer) extractErrorMessage(line string) string {
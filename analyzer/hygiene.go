@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"goqkview/graph"
+	"goqkview/parser"
+)
+
+type HygieneAnalyzer struct{}
+
+func NewHygieneAnalyzer() *HygieneAnalyzer {
+	return &HygieneAnalyzer{}
+}
+
+// Analyze walks the reference graph for objects nothing points at and for
+// references to objects that are not defined. Objects are reported by full
+// path.
+func (h *HygieneAnalyzer) Analyze(config *parser.BigIPConfig) ConfigHygiene {
+	result := ConfigHygiene{
+		UnusedPools:       []string{},
+		UnusedMonitors:    []string{},
+		UnusedProfiles:    []string{},
+		UnusedRules:       []string{},
+		UnusedDataGroups:  []string{},
+		OrphanNodes:       []string{},
+		MissingReferences: []MissingReference{},
+	}
+	if config == nil {
+		return result
+	}
+
+	g := graph.Build(config)
+
+	for _, node := range g.Nodes() {
+		if node.Missing {
+			for _, edge := range g.ReferencedBy(node.ID) {
+				from, _ := g.Node(edge.From)
+				result.MissingReferences = append(result.MissingReferences, MissingReference{
+					Kind:           string(node.Kind),
+					Name:           node.Path,
+					ReferencedBy:   from.Path,
+					ReferencedKind: string(from.Kind),
+					Relation:       edge.Relation,
+				})
+			}
+			continue
+		}
+		if node.Builtin || len(g.ReferencedBy(node.ID)) > 0 {
+			continue
+		}
+
+		switch node.Kind {
+		case graph.KindPool:
+			result.UnusedPools = append(result.UnusedPools, node.Path)
+		case graph.KindMonitor:
+			result.UnusedMonitors = append(result.UnusedMonitors, node.Path)
+		case graph.KindProfile:
+			result.UnusedProfiles = append(result.UnusedProfiles, node.Path)
+		case graph.KindRule:
+			result.UnusedRules = append(result.UnusedRules, node.Path)
+		case graph.KindDataGroup:
+			result.UnusedDataGroups = append(result.UnusedDataGroups, node.Path)
+		case graph.KindNode:
+			result.OrphanNodes = append(result.OrphanNodes, node.Path)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"goqkview/parser"
+)
+
+// Two partitions with a pool named app each: every reference has to land on
+// the pool of its own partition.
+const twoPartitionConfig = `ltm pool /Common/app {
+    monitor /Common/http
+}
+ltm pool /Tenant/app {
+    monitor /Tenant/app_monitor
+}
+ltm pool /Tenant/spare {
+    monitor /Common/tcp
+}
+ltm monitor http /Tenant/app_monitor {
+    defaults-from /Common/http
+}
+ltm monitor http /Common/app_monitor {
+    defaults-from /Common/http
+}
+ltm virtual /Common/vs {
+    destination /Common/10.1.1.1:80
+    pool /Common/app
+}
+ltm virtual /Tenant/vs {
+    destination /Tenant/10.1.1.2:80
+    pool /Tenant/app
+}
+ltm virtual /Tenant/vs2 {
+    destination /Tenant/10.1.1.3:80
+    pool /Tenant/gone
+}
+`
+
+func TestHygieneTwoPartitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bigip.conf")
+	if err := os.WriteFile(path, []byte(twoPartitionConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := parser.ParseBigIPConfig(path)
+	if err != nil {
+		t.Fatalf("ParseBigIPConfig: %v", err)
+	}
+
+	got := NewHygieneAnalyzer().Analyze(config)
+
+	if want := []string{"/Tenant/spare"}; !reflect.DeepEqual(got.UnusedPools, want) {
+		t.Errorf("UnusedPools = %v, want %v", got.UnusedPools, want)
+	}
+	if want := []string{"/Common/app_monitor"}; !reflect.DeepEqual(got.UnusedMonitors, want) {
+		t.Errorf("UnusedMonitors = %v, want %v", got.UnusedMonitors, want)
+	}
+	want := []MissingReference{{
+		Kind:           "pool",
+		Name:           "/Tenant/gone",
+		ReferencedBy:   "/Tenant/vs2",
+		ReferencedKind: "virtual",
+		Relation:       "pool",
+	}}
+	if !reflect.DeepEqual(got.MissingReferences, want) {
+		t.Errorf("MissingReferences = %+v, want %+v", got.MissingReferences, want)
+	}
+}
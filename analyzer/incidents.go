@@ -0,0 +1,198 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+// A timeline bucket is a burst when it holds at least burstMinLines error and
+// warning lines and lies more than burstMADs scaled median absolute
+// deviations above the median of the baselineBuckets before it. Anomalous
+// buckets at most mergeGap buckets apart form one incident.
+const (
+	baselineBuckets = 24
+	burstMinLines   = 5
+	burstMADs       = 3.0
+	mergeGap        = 1
+	dominantGroups  = 3
+)
+
+type IncidentDetector struct {
+	errors *ErrorAnalyzer
+}
+
+// NewIncidentDetector creates a detector that names error groups like errors.
+func NewIncidentDetector(errors *ErrorAnalyzer) *IncidentDetector {
+	return &IncidentDetector{errors: errors}
+}
+
+// Detect finds incident windows in the error timeline: bursts against a
+// rolling median/MAD baseline, and buckets in which an error group shows up
+// for the first time after a full baseline without it. Entries and config
+// supply the error groups and virtual servers of each window.
+func (d *IncidentDetector) Detect(timeline []TimelineEntry, resolution string, entries []interfaces.LogEntry, config *parser.BigIPConfig) []Incident {
+	incidents := []Incident{}
+	if len(timeline) == 0 {
+		return incidents
+	}
+
+	step := bucketStep(resolution)
+	starts := make([]time.Time, len(timeline))
+	counts := make(map[time.Time]int, len(timeline))
+	firstSeen := make(map[string]time.Time)
+	for i, bucket := range timeline {
+		starts[i] = bucketTime(bucket.Date, resolution)
+		counts[starts[i]] = bucket.Errors + bucket.Warnings
+		for group := range bucket.Groups {
+			if _, ok := firstSeen[group]; !ok {
+				firstSeen[group] = starts[i]
+			}
+		}
+	}
+	origin := starts[0]
+
+	var current *Incident
+	var last time.Time
+	for i, bucket := range timeline {
+		start := starts[i]
+		count := counts[start]
+
+		// The baseline is the buckets before this one, back to the start of
+		// the logs; buckets missing from the timeline had no lines.
+		var history []float64
+		for k := 1; k <= baselineBuckets; k++ {
+			before := start.Add(-time.Duration(k) * step)
+			if before.Before(origin) {
+				break
+			}
+			history = append(history, float64(counts[before]))
+		}
+		median, mad := medianMAD(history)
+		burst := len(history) > 0 && count >= burstMinLines &&
+			float64(count) > median+burstMADs*math.Max(1.4826*mad, 1)
+
+		var fresh []string
+		if len(history) == baselineBuckets {
+			for group := range bucket.Groups {
+				if firstSeen[group].Equal(start) {
+					fresh = append(fresh, group)
+				}
+			}
+		}
+
+		if !burst && len(fresh) == 0 {
+			continue
+		}
+		if current == nil || start.Sub(last) > time.Duration(mergeGap+1)*step {
+			incidents = append(incidents, Incident{Trigger: []string{}, NewGroups: []string{}, Baseline: median, start: start})
+			current = &incidents[len(incidents)-1]
+		}
+		last = start
+		current.end = start.Add(step)
+
+		if burst {
+			current.Trigger = appendUnique(current.Trigger, "burst")
+		}
+		if len(fresh) > 0 {
+			current.Trigger = appendUnique(current.Trigger, "new-error-group")
+			sort.Strings(fresh)
+			for _, group := range fresh {
+				current.NewGroups = appendUnique(current.NewGroups, group)
+			}
+		}
+		if count > current.PeakLines || current.Peak == "" {
+			current.PeakLines = count
+			current.PeakRate = float64(count) / step.Minutes()
+			current.Peak = start.Format("2006-01-02T15:04:05Z")
+		}
+	}
+
+	objects := newVirtualServerIndex(config)
+	for i := range incidents {
+		d.describe(&incidents[i], entries, objects)
+	}
+	return incidents
+}
+
+// describe fills in the window, line count, dominant error groups and
+// affected virtual servers from the entries inside the incident.
+func (d *IncidentDetector) describe(incident *Incident, entries []interfaces.LogEntry, objects virtualServerIndex) {
+	incident.Start = incident.start.Format("2006-01-02T15:04:05Z")
+	incident.End = incident.end.Format("2006-01-02T15:04:05Z")
+	incident.AffectedVS = []string{}
+
+	groups := make(map[string]int)
+	for _, entry := range entries {
+		if !isTimelineStatus(entry.Status) || entry.Timestamp.Before(incident.start) || !entry.Timestamp.Before(incident.end) {
+			continue
+		}
+		incident.Lines++
+		groups[d.errors.groupLabel(entry)]++
+		for _, vs := range objects.virtualServers(entry) {
+			incident.AffectedVS = appendUnique(incident.AffectedVS, vs)
+		}
+	}
+	sort.Strings(incident.AffectedVS)
+
+	incident.DominantGroups = make([]GroupCount, 0, len(groups))
+	for group, count := range groups {
+		incident.DominantGroups = append(incident.DominantGroups, GroupCount{Group: group, Count: count})
+	}
+	sort.Slice(incident.DominantGroups, func(i, j int) bool {
+		a, b := incident.DominantGroups[i], incident.DominantGroups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Group < b.Group
+	})
+	if len(incident.DominantGroups) > dominantGroups {
+		incident.DominantGroups = incident.DominantGroups[:dominantGroups]
+	}
+}
+
+func bucketStep(resolution string) time.Duration {
+	switch resolution {
+	case ResolutionMinute:
+		return time.Minute
+	case ResolutionHour:
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// bucketTime parses a timeline bucket label back into its start time.
+func bucketTime(label, resolution string) time.Time {
+	layout := "2006-01-02T15:04:05Z"
+	if resolution == ResolutionDay {
+		layout = "2006-01-02"
+	}
+	t, _ := time.Parse(layout, label)
+	return t
+}
+
+// medianMAD returns the median of values and their median absolute deviation.
+func medianMAD(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	median := medianOf(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, medianOf(deviations)
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncidentDetect(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// quiet is a full baseline of one line an hour in group A.
+	quiet := func() []int {
+		counts := make([]int, baselineBuckets)
+		for i := range counts {
+			counts[i] = 1
+		}
+		return counts
+	}
+
+	tests := []struct {
+		name   string
+		counts []int          // Error lines per hourly bucket
+		groups map[int]string // Bucket -> an extra group first seen there
+		want   []string       // Start, end, triggers, new groups and peak lines of each incident
+	}{
+		{
+			name:   "burst after a quiet baseline",
+			counts: append(quiet(), 20, 1),
+			want:   []string{"24:00-25:00 burst [] 20"},
+		},
+		{
+			name:   "burst below the minimum lines",
+			counts: append(make([]int, baselineBuckets), burstMinLines-1),
+		},
+		{
+			name:   "no baseline before the first bucket",
+			counts: []int{50, 1},
+		},
+		{
+			name:   "bursts one gap apart merge",
+			counts: append(quiet(), 10, 1, 12),
+			want:   []string{"24:00-27:00 burst [] 12"},
+		},
+		{
+			name:   "bursts two gaps apart stay separate",
+			counts: append(quiet(), 10, 1, 1, 12),
+			want:   []string{"24:00-25:00 burst [] 10", "27:00-28:00 burst [] 12"},
+		},
+		{
+			name:   "new group after a full baseline",
+			counts: append(quiet(), 1),
+			groups: map[int]string{baselineBuckets: "B"},
+			want:   []string{"24:00-25:00 new-error-group [B] 1"},
+		},
+		{
+			name:   "new group before a full baseline",
+			counts: append(quiet(), 1),
+			groups: map[int]string{baselineBuckets - 1: "B"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timeline []TimelineEntry
+			for i, count := range tt.counts {
+				groups := map[string]int{"A": count}
+				if group, ok := tt.groups[i]; ok {
+					groups[group] = 1
+				}
+				timeline = append(timeline, TimelineEntry{
+					Date:   base.Add(time.Duration(i) * time.Hour).Format("2006-01-02T15:04:05Z"),
+					Errors: count,
+					Groups: groups,
+				})
+			}
+
+			// Hours since base, so that buckets past a day stay readable.
+			hours := func(stamp string) string {
+				at, _ := time.Parse("2006-01-02T15:04:05Z", stamp)
+				return fmt.Sprintf("%02d:00", int(at.Sub(base).Hours()))
+			}
+			var got []string
+			for _, incident := range NewIncidentDetector(NewErrorAnalyzer(nil)).Detect(timeline, ResolutionHour, nil, nil) {
+				got = append(got, fmt.Sprintf("%s-%s %s %v %d", hours(incident.Start), hours(incident.End),
+					strings.Join(incident.Trigger, ","), incident.NewGroups, incident.PeakLines))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianMAD(t *testing.T) {
+	tests := []struct {
+		values      []float64
+		median, mad float64
+	}{
+		{nil, 0, 0},
+		{[]float64{3}, 3, 0},
+		{[]float64{1, 1, 2, 2, 4, 6, 9}, 2, 1},
+		{[]float64{4, 1, 3, 2}, 2.5, 1},
+	}
+	for _, tt := range tests {
+		if median, mad := medianMAD(tt.values); median != tt.median || mad != tt.mad {
+			t.Errorf("medianMAD(%v) = %v, %v, want %v, %v", tt.values, median, mad, tt.median, tt.mad)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"goqkview/graph"
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+type IRuleAnalyzer struct {
+	eventPattern      *regexp.Regexp
+	deprecatedPattern *regexp.Regexp
+	unbracedPattern   *regexp.Regexp
+	globalVarPattern  *regexp.Regexp
+	tclErrorPattern   *regexp.Regexp
+}
+
+// Deprecated commands and their replacements.
+var deprecatedIRuleCommands = map[string]string{
+	"matchclass":  "class match",
+	"findclass":   "class search / class lookup",
+	"http_uri":    "HTTP::uri",
+	"http_host":   "HTTP::host",
+	"http_header": "HTTP::header",
+	"http_method": "HTTP::method",
+	"http_cookie": "HTTP::cookie",
+	"client_addr": "IP::client_addr",
+	"server_addr": "IP::server_addr",
+	"remote_addr": "IP::remote_addr",
+	"local_addr":  "IP::local_addr",
+	"client_port": "TCP::client_port",
+	"server_port": "TCP::server_port",
+	"ip_protocol": "IP::protocol",
+	"use":         "pool / node / snat",
+}
+
+func NewIRuleAnalyzer() *IRuleAnalyzer {
+	names := make([]string, 0, len(deprecatedIRuleCommands))
+	for name := range deprecatedIRuleCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return &IRuleAnalyzer{
+		eventPattern:      regexp.MustCompile(`^\s*when\s+(\w+)`),
+		deprecatedPattern: regexp.MustCompile(`(?:^|[\[{};])\s*(` + strings.Join(names, "|") + `)(?:\s|\]|$)`),
+		unbracedPattern:   regexp.MustCompile(`(?:^|[\[{};])\s*(if|elseif|while|expr)\s+([^\s{])`),
+		globalVarPattern:  regexp.MustCompile(`(?:\$::|set\s+::|global\s+)(\w+)`),
+		tclErrorPattern:   regexp.MustCompile(`TCL error:\s+(/\S+)\s+<(\w+)>\s*-\s*(.*)`),
+	}
+}
+
+func (ia *IRuleAnalyzer) Analyze(config *parser.BigIPConfig, entries []interfaces.LogEntry) []IRuleReport {
+	if config == nil {
+		return []IRuleReport{}
+	}
+
+	reports := make(map[string]*IRuleReport, len(config.ByPath.Rules))
+	for path, rule := range config.ByPath.Rules {
+		report := &IRuleReport{Name: rule.Name, Path: path, VirtualServers: []string{}, Events: []string{}, Findings: []IRuleFinding{}}
+		ia.check(rule, config, report)
+		reports[path] = report
+	}
+
+	for vsPath, vs := range config.ByPath.VirtualServers {
+		for _, rule := range vs.RulePaths {
+			if report, ok := reports[rule]; ok {
+				report.VirtualServers = appendUnique(report.VirtualServers, vsPath)
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		matches := ia.tclErrorPattern.FindStringSubmatch(messageText(entry))
+		if matches == nil {
+			continue
+		}
+		report, ok := reports[matches[1]]
+		if !ok {
+			continue
+		}
+		report.TCLErrors++
+		if entry.Timestamp.After(report.lastTCLError) || report.LastTCLError == "" {
+			report.lastTCLError = entry.Timestamp
+			report.LastTCLError = fmt.Sprintf("<%s> %s - %s", matches[2], truncate(matches[3], 150),
+				entry.Timestamp.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	result := make([]IRuleReport, 0, len(reports))
+	for _, path := range sortedKeys(reports) {
+		sort.Strings(reports[path].VirtualServers)
+		result = append(result, *reports[path])
+	}
+	return result
+}
+
+func (ia *IRuleAnalyzer) check(rule *parser.RuleConfig, config *parser.BigIPConfig, report *IRuleReport) {
+	lines := strings.Split(rule.Body, "\n")
+	events := ia.splitEvents(lines)
+
+	for _, ev := range events {
+		report.Events = appendUnique(report.Events, ev.name)
+	}
+
+	add := func(severity, check string, line int, event, format string, args ...any) {
+		report.Findings = append(report.Findings, IRuleFinding{
+			Severity: severity,
+			Check:    check,
+			Line:     line,
+			Event:    event,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	eventAt := func(line int) string {
+		for _, ev := range events {
+			if line >= ev.start && line <= ev.end {
+				return ev.name
+			}
+		}
+		return ""
+	}
+
+	reportedGlobals := make(map[string]bool)
+	for i, raw := range lines {
+		lineNo := i + 1
+		code := strings.TrimSpace(raw)
+		if code == "" || strings.HasPrefix(code, "#") {
+			continue
+		}
+		event := eventAt(lineNo)
+
+		for _, m := range ia.deprecatedPattern.FindAllStringSubmatch(code, -1) {
+			add("warning", "deprecated-command", lineNo, event,
+				"Deprecated command %s; use %s", m[1], deprecatedIRuleCommands[m[1]])
+		}
+
+		for _, m := range ia.unbracedPattern.FindAllStringSubmatch(code, -1) {
+			add("warning", "unbraced-expression", lineNo, event,
+				"Unbraced %s expression causes double substitution and is not byte-compiled", m[1])
+		}
+
+		for _, m := range ia.globalVarPattern.FindAllStringSubmatch(code, -1) {
+			if reportedGlobals[m[1]] {
+				continue
+			}
+			reportedGlobals[m[1]] = true
+			add("warning", "cmp-global-variable", lineNo, event,
+				"Global variable ::%s demotes the virtual server from CMP; use static:: or the session table", m[1])
+		}
+
+		if event == "CLIENT_ACCEPTED" && strings.Contains(code, "HTTP::respond") {
+			add("critical", "http-in-client-accepted", lineNo, event,
+				"HTTP::respond in CLIENT_ACCEPTED runs before the HTTP request is parsed")
+		}
+
+		if strings.Contains(code, "HTTP::redirect") && ia.codeFollows(lines, i, eventEnd(events, lineNo)) {
+			add("warning", "redirect-without-return", lineNo, event,
+				"HTTP::redirect is not followed by return; later commands in %s still run", event)
+		}
+	}
+
+	dataGroupDefined := func(path string) bool { _, ok := config.ByPath.DataGroups[path]; return ok }
+	poolDefined := func(path string) bool { _, ok := config.ByPath.Pools[path]; return ok }
+	for _, ref := range rule.References() {
+		switch ref.Kind {
+		case "data-group":
+			path := rule.ReferencePath(ref, dataGroupDefined)
+			builtin := parser.PartitionOf(path) == "Common" && graph.IsBuiltin(graph.KindDataGroup, parser.CleanName(path))
+			if !dataGroupDefined(path) && !builtin {
+				add("critical", "undefined-data-group", ref.Line, eventAt(ref.Line), "Data group %s is not defined", path)
+			}
+		case "pool":
+			if path := rule.ReferencePath(ref, poolDefined); !poolDefined(path) {
+				add("critical", "undefined-pool", ref.Line, eventAt(ref.Line), "Pool %s is not defined", path)
+			}
+		}
+	}
+}
+
+// codeFollows reports whether any statement other than return or
+// "event disable" runs after lines[idx] before the end of the event.
+func (ia *IRuleAnalyzer) codeFollows(lines []string, idx, end int) bool {
+	for j := idx + 1; j < len(lines) && j < end; j++ {
+		code := strings.TrimSpace(lines[j])
+		code = strings.Trim(code, "{} \t")
+		if code == "" || strings.HasPrefix(code, "#") {
+			continue
+		}
+		if code == "return" || strings.HasPrefix(code, "event disable") || strings.HasPrefix(code, "else") {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+type ruleEvent struct {
+	name       string
+	start, end int // 1-based line range of the when block
+}
+
+func (ia *IRuleAnalyzer) splitEvents(lines []string) []ruleEvent {
+	var events []ruleEvent
+	depth := 0
+	current := -1
+
+	for i, raw := range lines {
+		code := strings.TrimSpace(raw)
+		if strings.HasPrefix(code, "#") {
+			continue
+		}
+		if depth == 0 {
+			if m := ia.eventPattern.FindStringSubmatch(code); m != nil {
+				events = append(events, ruleEvent{name: m[1], start: i + 1, end: len(lines)})
+				current = len(events) - 1
+			}
+		}
+		depth += parser.CountTCLBraces(code)
+		if depth <= 0 {
+			if current != -1 && i+1 > events[current].start {
+				events[current].end = i + 1
+				current = -1
+			}
+			depth = 0
+		}
+	}
+	return events
+}
+
+func eventEnd(events []ruleEvent, line int) int {
+	for _, ev := range events {
+		if line >= ev.start && line <= ev.end {
+			return ev.end
+		}
+	}
+	return line
+}
@@ -0,0 +1,222 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"goqkview/parser"
+)
+
+type listener struct {
+	vs       string
+	dest     parser.Destination
+	prefix   netip.Prefix
+	source   string
+	protocol string
+}
+
+// checkListeners parses every destination and attaches conflict, coverage
+// and virtual-address findings to the matching VirtualServerInfo.
+func (v *VirtualServerAnalyzer) checkListeners(config *parser.BigIPConfig, results []VirtualServerInfo) {
+	byPath := make(map[string]*VirtualServerInfo, len(results))
+	for i := range results {
+		byPath[results[i].Path] = &results[i]
+	}
+
+	add := func(vs, severity, check, format string, args ...any) {
+		info, ok := byPath[vs]
+		if !ok {
+			return
+		}
+		info.Findings = append(info.Findings, VirtualServerFinding{
+			Severity: severity,
+			Check:    check,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	var listeners []listener
+	for _, path := range sortedKeys(config.ByPath.VirtualServers) {
+		vs := config.ByPath.VirtualServers[path]
+		if vs.Destination == "" {
+			continue
+		}
+		dest, err := config.VirtualDestination(vs)
+		if err != nil {
+			add(path, "warning", "invalid-destination", "Cannot parse destination %s: %v", vs.Destination, err)
+			continue
+		}
+		if info, ok := byPath[path]; ok {
+			info.Destination = dest.String()
+		}
+		if !dest.Address.IsValid() {
+			add(path, "warning", "unresolved-destination", "Virtual address %s is not defined", dest.Name)
+			continue
+		}
+
+		v.checkVirtualAddress(config, vs, dest, add)
+		if vs.Disabled {
+			continue
+		}
+
+		bits := parser.MaskBits(vs.Mask, dest.Address)
+		if dest.Wildcard() {
+			bits = 0
+		}
+		prefix, _ := dest.Address.Prefix(bits)
+		l := listener{vs: path, dest: dest, prefix: prefix, source: vs.Source, protocol: vs.IPProtocol}
+		if l.source == "" {
+			l.source = "0.0.0.0/0"
+		}
+		if l.protocol == "" {
+			l.protocol = "tcp"
+		}
+		listeners = append(listeners, l)
+
+		if bits == dest.Address.BitLen() {
+			v.checkCoverage(config, path, dest, add)
+		}
+	}
+
+	shadowedBy := make(map[string][]string)
+	for i := range listeners {
+		for j := i + 1; j < len(listeners); j++ {
+			a, b := listeners[i], listeners[j]
+
+			if a.dest.RouteDomain != b.dest.RouteDomain {
+				if a.dest.Address == b.dest.Address && a.dest.Port == b.dest.Port && !a.dest.Wildcard() {
+					add(a.vs, "info", "cross-route-domain", "Shares %s with %s in route domain %d", withoutRouteDomain(a.dest), b.vs, b.dest.RouteDomain)
+					add(b.vs, "info", "cross-route-domain", "Shares %s with %s in route domain %d", withoutRouteDomain(b.dest), a.vs, a.dest.RouteDomain)
+				}
+				continue
+			}
+			if a.protocol != b.protocol && a.protocol != "any" && b.protocol != "any" {
+				continue
+			}
+			if a.source != b.source || !a.prefix.Overlaps(b.prefix) || !portsOverlap(a.dest.Port, b.dest.Port) {
+				continue
+			}
+
+			// Same prefix, port and route domain is a duplicate whether or not
+			// one side listens on any protocol; neither is more specific.
+			if a.prefix == b.prefix && a.dest.Port == b.dest.Port {
+				add(a.vs, "critical", "duplicate-listener", "Listens on %s, same as %s", a.dest, b.vs)
+				add(b.vs, "critical", "duplicate-listener", "Listens on %s, same as %s", b.dest, a.vs)
+				continue
+			}
+
+			// BIG-IP sends a connection to the most specific listener:
+			// longest address mask first, then a specific port over any.
+			if moreSpecific(a, b) {
+				shadowedBy[b.vs] = append(shadowedBy[b.vs], a.vs)
+			} else {
+				shadowedBy[a.vs] = append(shadowedBy[a.vs], b.vs)
+			}
+		}
+	}
+
+	for _, l := range listeners {
+		others := shadowedBy[l.vs]
+		if len(others) == 0 {
+			continue
+		}
+		sort.Strings(others)
+		severity := "info"
+		if l.prefix.Bits() == l.dest.Address.BitLen() {
+			severity = "warning"
+		}
+		add(l.vs, severity, "shadowed-listener", "Traffic within %s is taken by %d more specific virtual server(s): %s",
+			listenerRange(l), len(others), strings.Join(limitList(others, 5), ", "))
+	}
+}
+
+func (v *VirtualServerAnalyzer) checkVirtualAddress(config *parser.BigIPConfig, virtual *parser.VirtualServerConfig, dest parser.Destination, add func(vs, severity, check, format string, args ...any)) {
+	va, ok := config.VirtualAddressOf(virtual, dest)
+	if !ok {
+		return
+	}
+	vs := virtual.Path
+
+	if !va.ARP && !dest.Wildcard() {
+		add(vs, "warning", "arp-disabled", "Virtual address %s has ARP disabled; hosts on the local subnet cannot reach it unless it is routed", va.Path)
+	}
+	if va.ICMPEcho == "disabled" {
+		add(vs, "info", "icmp-disabled", "Virtual address %s does not answer ping", va.Path)
+	}
+	if !va.Enabled {
+		add(vs, "warning", "virtual-address-disabled", "Virtual address %s is disabled", va.Path)
+	}
+}
+
+func (v *VirtualServerAnalyzer) checkCoverage(config *parser.BigIPConfig, vs string, dest parser.Destination, add func(vs, severity, check, format string, args ...any)) {
+	if len(config.SelfIPs) == 0 {
+		return // No bigip_base.conf in the qkview
+	}
+
+	if len(config.RouteDomains) > 0 {
+		if _, ok := config.RouteDomains[dest.RouteDomain]; !ok {
+			add(vs, "warning", "unknown-route-domain", "Route domain %d is not defined", dest.RouteDomain)
+			return
+		}
+	}
+
+	for _, path := range sortedKeys(config.SelfIPs) {
+		self := config.SelfIPs[path]
+		prefix, rd, err := parser.ParsePrefix(self.Address)
+		if err != nil || rd != dest.RouteDomain || !prefix.Contains(dest.Address) {
+			continue
+		}
+		if len(config.VLANs) > 0 && self.VLANPath != "" {
+			if _, ok := config.VLANs[self.VLANPath]; !ok {
+				add(vs, "warning", "missing-vlan", "Self IP %s covering %s is on VLAN %s, which is not defined", path, dest.Address, self.VLANPath)
+			}
+		}
+		return
+	}
+
+	add(vs, "warning", "uncovered-destination", "Destination %s is not in any self IP subnet in route domain %d; it is reachable only if routed to the BIG-IP",
+		dest.Address, dest.RouteDomain)
+}
+
+func moreSpecific(a, b listener) bool {
+	if a.prefix.Bits() != b.prefix.Bits() {
+		return a.prefix.Bits() > b.prefix.Bits()
+	}
+	return a.dest.Port != 0 && b.dest.Port == 0
+}
+
+func portsOverlap(a, b int) bool {
+	return a == 0 || b == 0 || a == b
+}
+
+func withoutRouteDomain(d parser.Destination) string {
+	d.RouteDomain = 0
+	return d.String()
+}
+
+func listenerRange(l listener) string {
+	port := "any"
+	if l.dest.Port != 0 {
+		port = strconv.Itoa(l.dest.Port)
+	}
+	return fmt.Sprintf("%s port %s", l.prefix, port)
+}
+
+func limitList(items []string, max int) []string {
+	if len(items) <= max {
+		return items
+	}
+	return append(items[:max:max], fmt.Sprintf("and %d more", len(items)-max))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
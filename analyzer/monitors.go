@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+type MonitorAnalyzer struct {
+	transitionPattern *regexp.Regexp
+}
+
+func NewMonitorAnalyzer() *MonitorAnalyzer {
+	return &MonitorAnalyzer{
+		// Pool /Common/p member /Common/10.0.0.1:80 monitor status down. [ ... ]
+		transitionPattern: regexp.MustCompile(`Pool\s+(/\S+)\s+member\s+(/\S+)\s+monitor status\s+([a-z ]+?)\s*[.\[]`),
+	}
+}
+
+// Analyze builds a per-member timeline of monitor state changes. Outages
+// still open at the end of the logs are counted up to the reference time,
+// or the last log entry when that is later.
+func (m *MonitorAnalyzer) Analyze(entries []interfaces.LogEntry, reference time.Time) []MemberHistory {
+	histories := make(map[string]*MemberHistory)
+	windowEnd := reference
+
+	for _, entry := range entries {
+		if entry.Timestamp.After(windowEnd) {
+			windowEnd = entry.Timestamp
+		}
+
+		matches := m.transitionPattern.FindStringSubmatch(messageText(entry))
+		if matches == nil {
+			continue
+		}
+
+		pool := matches[1]
+		member := parser.CleanName(matches[2])
+		key := memberKey(pool, member)
+
+		history, ok := histories[key]
+		if !ok {
+			history = &MemberHistory{Pool: pool, Member: member}
+			histories[key] = history
+		}
+		history.Transitions = append(history.Transitions, MemberStateChange{
+			Time:  entry.Timestamp,
+			State: monitorState(matches[3]),
+		})
+	}
+
+	result := make([]MemberHistory, 0, len(histories))
+	for _, history := range histories {
+		m.summarize(history, windowEnd)
+		result = append(result, *history)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FlapCount != result[j].FlapCount {
+			return result[i].FlapCount > result[j].FlapCount
+		}
+		return memberKey(result[i].Pool, result[i].Member) < memberKey(result[j].Pool, result[j].Member)
+	})
+
+	return result
+}
+
+func (m *MonitorAnalyzer) summarize(h *MemberHistory, windowEnd time.Time) {
+	sort.SliceStable(h.Transitions, func(i, j int) bool {
+		return h.Transitions[i].Time.Before(h.Transitions[j].Time)
+	})
+
+	var downSince time.Time
+	var total, longest time.Duration
+	previous := ""
+
+	closeOutage := func(at time.Time) {
+		if downSince.IsZero() {
+			return
+		}
+		outage := at.Sub(downSince)
+		total += outage
+		if outage > longest {
+			longest = outage
+		}
+		downSince = time.Time{}
+	}
+
+	for _, change := range h.Transitions {
+		isDown := change.State == "down" || change.State == "forced-offline"
+		if isDown && previous != "down" && previous != "forced-offline" {
+			// Any state but down counts: a member enabled as unchecked or
+			// unknown that fails its first check has flapped too.
+			h.FlapCount++
+			downSince = change.Time
+		} else if !isDown {
+			closeOutage(change.Time)
+		}
+		previous = change.State
+	}
+	closeOutage(windowEnd)
+
+	h.CurrentState = previous
+	h.DowntimeSeconds = int64(total.Seconds())
+	h.LongestOutageSeconds = int64(longest.Seconds())
+	h.Summary = fmt.Sprintf("member %s flapped %d times, down %s total (longest %s), currently %s",
+		h.Member, h.FlapCount, formatDuration(total), formatDuration(longest), h.CurrentState)
+}
+
+func monitorState(status string) string {
+	switch s := strings.TrimSpace(status); {
+	case strings.Contains(s, "forced"):
+		return "forced-offline"
+	case strings.Contains(s, "down"):
+		return "down"
+	case strings.Contains(s, "up"):
+		return "up"
+	default:
+		return "unchecked"
+	}
+}
+
+func memberKey(pool, member string) string {
+	return pool + "|" + member
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	h := int(d.Hours())
+	mins := int(d.Minutes()) % 60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", mins)
+	case mins == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%dm", h, mins)
+}
@@ -0,0 +1,199 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+type NodeAnalyzer struct {
+	transitionPattern *regexp.Regexp
+}
+
+func NewNodeAnalyzer() *NodeAnalyzer {
+	return &NodeAnalyzer{
+		// Node /Common/10.0.0.1 address 10.0.0.1 monitor status down. [ ... ]
+		transitionPattern: regexp.MustCompile(`Node\s+(/\S+)\s+address\s+\S+\s+monitor status\s+([a-z ]+?)\s*[.\[]`),
+	}
+}
+
+// Analyze rolls pool member health up to the backend node, so a single
+// server outage that affects several pools and virtual servers stands out.
+func (n *NodeAnalyzer) Analyze(config *parser.BigIPConfig, entries []interfaces.LogEntry, vsInfo []VirtualServerInfo) []NodeHealth {
+	if config == nil {
+		return []NodeHealth{}
+	}
+
+	// Keyed by full path: /Common/10.0.0.5 and /Tenant/10.0.0.5 are
+	// different nodes.
+	nodes := make(map[string]*NodeHealth)
+	getNode := func(path, name string) *NodeHealth {
+		if node, ok := nodes[path]; ok {
+			return node
+		}
+		node := &NodeHealth{Name: name, Path: path, Address: name}
+		nodes[path] = node
+		return node
+	}
+
+	for path, cfg := range config.ByPath.Nodes {
+		node := getNode(path, cfg.Name)
+		node.Address = cfg.Address
+		node.Monitor = cfg.Monitor
+		switch {
+		case cfg.Down:
+			node.State = "down"
+			node.Reason = "user-down"
+		case cfg.Disabled:
+			node.State = "disabled"
+			node.Reason = "user-disabled"
+		}
+	}
+
+	offlineVS := make(map[string]bool)
+	for _, vs := range vsInfo {
+		if vs.Availability == "offline" {
+			offlineVS[vs.Path] = true
+		}
+	}
+
+	poolToVS := make(map[string][]string)
+	for path, vs := range config.ByPath.VirtualServers {
+		if vs.PoolPath != "" {
+			poolToVS[vs.PoolPath] = append(poolToVS[vs.PoolPath], path)
+		}
+	}
+
+	for poolPath, pool := range config.ByPath.Pools {
+		for _, m := range pool.Members {
+			node := getNode(m.NodePath(), m.NodeName())
+			if node.Address == node.Name && m.Address != "" {
+				node.Address = m.Address
+			}
+			node.TotalMembers++
+			if !m.Available() {
+				node.UnavailableMembers++
+			}
+			node.Pools = appendUnique(node.Pools, poolPath)
+			for _, vs := range poolToVS[poolPath] {
+				node.VirtualServers = appendUnique(node.VirtualServers, vs)
+				if offlineVS[vs] && !m.Available() {
+					node.OfflineVS = appendUnique(node.OfflineVS, vs)
+				}
+			}
+		}
+	}
+
+	n.correlateLogs(nodes, entries)
+
+	result := make([]NodeHealth, 0, len(nodes))
+	for _, node := range nodes {
+		n.finalize(node)
+		result = append(result, *node)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].VirtualServers) != len(result[j].VirtualServers) {
+			return len(result[i].VirtualServers) > len(result[j].VirtualServers)
+		}
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+func (n *NodeAnalyzer) correlateLogs(nodes map[string]*NodeHealth, entries []interfaces.LogEntry) {
+	for _, entry := range entries {
+		message := messageText(entry)
+		if matches := n.transitionPattern.FindStringSubmatch(message); matches != nil {
+			if node, ok := nodes[matches[1]]; ok && !entry.Timestamp.Before(node.lastTransition) {
+				node.lastTransition = entry.Timestamp
+				node.monitorState = monitorState(matches[2])
+			}
+		}
+
+		seen := make(map[*NodeHealth]bool)
+		for _, ref := range entry.Objects {
+			path := ref.Path
+			switch ref.Kind {
+			case parser.ObjectNode:
+			case parser.ObjectMember:
+				path = parser.PoolMember{Name: ref.Name, Path: ref.Path}.NodePath()
+			default:
+				continue
+			}
+			node, ok := nodes[path]
+			if !ok || seen[node] {
+				continue
+			}
+			seen[node] = true
+			node.LogEvents++
+			if isErrorStatus(entry.Status) {
+				node.ErrorEvents++
+			}
+			if entry.Timestamp.After(node.lastEvent) {
+				node.lastEvent = entry.Timestamp
+				node.LastEvent = fmt.Sprintf("%s - %s", truncate(message, 150), entry.Timestamp.Format("2006-01-02 15:04:05"))
+			}
+		}
+	}
+}
+
+func (n *NodeAnalyzer) finalize(node *NodeHealth) {
+	sort.Strings(node.Pools)
+	sort.Strings(node.VirtualServers)
+	sort.Strings(node.OfflineVS)
+
+	if node.State == "" {
+		switch {
+		case node.monitorState == "down" || node.monitorState == "forced-offline":
+			node.State = "down"
+			node.Reason = "node monitor " + node.monitorState
+		case node.TotalMembers > 0 && node.UnavailableMembers == node.TotalMembers:
+			node.State = "down"
+			node.Reason = fmt.Sprintf("all %d pool members unavailable", node.TotalMembers)
+		case node.UnavailableMembers > 0:
+			node.State = "degraded"
+			node.Reason = fmt.Sprintf("%d of %d pool members unavailable", node.UnavailableMembers, node.TotalMembers)
+		default:
+			node.State = "up"
+		}
+	}
+
+	if len(node.VirtualServers) > 1 {
+		node.Impact = "multi-service"
+	}
+	if node.State == "down" && len(node.VirtualServers) > 1 {
+		node.Finding = fmt.Sprintf("Backend %s is down and is shared by %d virtual servers (%s)",
+			node.Address, len(node.VirtualServers), strings.Join(node.VirtualServers, ", "))
+		if len(node.OfflineVS) > 0 {
+			node.Finding += fmt.Sprintf("; %d offline as a result", len(node.OfflineVS))
+		}
+	}
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// truncate cuts s to at most max bytes, backing off to a rune boundary so
+// that log text stays valid UTF-8.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"pool member down", 4, "pool..."},
+		{"café au lait", 4, "caf..."},  // é is two bytes; the cut falls inside it
+		{"café au lait", 5, "café..."}, // ... and right after it
+		{"日本語", 4, "日..."},
+		{"日本語", 2, "..."},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.max)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) = %q is not valid UTF-8", tt.s, tt.max, got)
+		}
+	}
+}
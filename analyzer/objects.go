@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"strings"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+// objectMentions are the virtual servers, pools and pool members a log line
+// names, by full path. Members are written pool/member.
+type objectMentions struct {
+	virtualServers []string
+	pools          []string
+	members        []string
+}
+
+// mentions reads the objects the parser matched in the entry's message.
+func mentions(entry interfaces.LogEntry) objectMentions {
+	var m objectMentions
+	for _, ref := range entry.Objects {
+		switch ref.Kind {
+		case parser.ObjectVirtual:
+			m.virtualServers = appendUnique(m.virtualServers, ref.Path)
+		case parser.ObjectPool:
+			m.pools = appendUnique(m.pools, ref.Path)
+		case parser.ObjectMember:
+			m.members = appendUnique(m.members, ref.Pool+"/"+ref.Name)
+		}
+	}
+	return m
+}
+
+// logFile is the path of a log file inside the qkview, e.g. var/log/ltm.
+func logFile(path string) string {
+	path = strings.ReplaceAll(path, "\\", "/")
+	if i := strings.LastIndex(path, "/var/log/"); i >= 0 {
+		return path[i+1:]
+	}
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// virtualServerIndex maps configuration objects to the full paths of the
+// virtual servers behind them: the virtual server itself, its pool with the
+// pool's members, nodes and monitors, and its iRules and profiles. Objects
+// are keyed by full path, members by pool path and member name.
+type virtualServerIndex map[string][]string
+
+func objectKey(kind, path string) string {
+	return kind + ":" + path
+}
+
+func newVirtualServerIndex(config *parser.BigIPConfig) virtualServerIndex {
+	index := make(virtualServerIndex)
+	if config == nil {
+		return index
+	}
+
+	add := func(kind, path, vs string) {
+		key := objectKey(kind, path)
+		index[key] = appendUnique(index[key], vs)
+	}
+	for path, vs := range config.ByPath.VirtualServers {
+		add(parser.ObjectVirtual, path, path)
+		if pool, ok := config.ByPath.Pools[vs.PoolPath]; ok {
+			add(parser.ObjectPool, pool.Path, path)
+			for _, m := range pool.Members {
+				add(parser.ObjectMember, memberKey(pool.Path, m.Name), path)
+				add(parser.ObjectNode, m.NodePath(), path)
+			}
+			for _, monitor := range pool.MonitorPaths {
+				add(parser.ObjectMonitor, monitor, path)
+			}
+		} else if vs.PoolPath != "" {
+			add(parser.ObjectPool, vs.PoolPath, path)
+		}
+		for _, rule := range vs.RulePaths {
+			add(parser.ObjectRule, rule, path)
+		}
+		for _, profile := range vs.ProfilePaths {
+			add(parser.ObjectProfile, profile, path)
+		}
+	}
+	return index
+}
+
+// lookup returns the virtual servers behind ref.
+func (x virtualServerIndex) lookup(ref interfaces.ObjectRef) []string {
+	path := ref.Path
+	if ref.Kind == parser.ObjectMember {
+		path = memberKey(ref.Pool, ref.Name)
+	}
+	return x[objectKey(ref.Kind, path)]
+}
+
+func (x virtualServerIndex) virtualServers(entry interfaces.LogEntry) []string {
+	var result []string
+	for _, ref := range entry.Objects {
+		for _, vs := range x.lookup(ref) {
+			result = appendUnique(result, vs)
+		}
+	}
+	return result
+}
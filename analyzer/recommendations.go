@@ -2,6 +2,8 @@ package analyzer
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -11,7 +13,7 @@ func NewRecommender() *Recommender {
 	return &Recommender{}
 }
 
-func (r *Recommender) Generate(summary Summary, sslFindings []SSLFinding, topErrors []TopError) []Recommendation {
+func (r *Recommender) Generate(summary Summary, sslFindings []SSLFinding, topErrors []TopError, nodes []NodeHealth, irules []IRuleReport, changes []ConfigChange, incidents []Incident, ruleFindings []RuleFinding) []Recommendation {
 	recommendations := []Recommendation{}
 
 	for _, finding := range sslFindings {
@@ -28,6 +30,34 @@ func (r *Recommender) Generate(summary Summary, sslFindings []SSLFinding, topErr
 		}
 	}
 
+	for _, node := range nodes {
+		if node.Finding != "" {
+			recommendations = append(recommendations, Recommendation{
+				Priority:    "critical",
+				Title:       "Shared backend outage",
+				Description: node.Finding + ". Restore the server or take it out of rotation.",
+				Impact:      r.formatAffectedVS(node.VirtualServers),
+			})
+		}
+	}
+
+	for _, rule := range irules {
+		rec := r.iruleRecommendation(rule)
+		if rec.Title != "" {
+			recommendations = append(recommendations, rec)
+		}
+	}
+
+	if rec := r.incidentRecommendation(incidents); rec.Title != "" {
+		recommendations = append(recommendations, rec)
+	}
+
+	if rec := r.changeRecommendation(changes); rec.Title != "" {
+		recommendations = append(recommendations, rec)
+	}
+
+	recommendations = append(recommendations, r.ruleRecommendations(ruleFindings)...)
+
 	if summary.Critical > 10 {
 		recommendations = append(recommendations, Recommendation{
 			Priority:    "critical",
@@ -84,8 +114,15 @@ func (r *Recommender) sslRecommendation(finding SSLFinding) Recommendation {
 	return Recommendation{}
 }
 
+// recurringErrors is how often an error must occur before it is worth a
+// recommendation of its own, unless the catalog rates it critical or error.
+const recurringErrors = 10
+
 func (r *Recommender) errorRecommendation(err TopError) Recommendation {
-	if err.Count < 10 {
+	if err.Action != "" {
+		return r.catalogRecommendation(err)
+	}
+	if err.Count < recurringErrors {
 		return Recommendation{}
 	}
 
@@ -105,6 +142,189 @@ func (r *Recommender) errorRecommendation(err TopError) Recommendation {
 	}
 }
 
+// catalogRecommendation uses the catalog's advice for a known message ID. The
+// catalog severity sets the priority; a few occurrences of a critical
+// condition matter as much as many of a minor one. Warning and info entries,
+// such as a single monitor flap, still need to recur.
+func (r *Recommender) catalogRecommendation(err TopError) Recommendation {
+	priority := map[string]string{
+		"critical": "critical",
+		"error":    "high",
+	}[err.Severity]
+	if priority == "" {
+		if err.Count < recurringErrors {
+			return Recommendation{}
+		}
+		priority = "medium"
+		if err.Severity == "info" {
+			priority = "low"
+		}
+	}
+
+	title := err.Meaning
+	if err.Component != "" {
+		title = err.Component + ": " + err.Meaning
+	}
+
+	return Recommendation{
+		Priority:    priority,
+		Title:       title,
+		Description: fmt.Sprintf("Message %s occurred %d times (%s). %s", err.MessageID, err.Count, err.Message, err.Action),
+		Impact:      fmt.Sprintf("Last occurred: %s", err.LastOccurred.Format("2006-01-02 15:04:05")),
+	}
+}
+
+func (r *Recommender) iruleRecommendation(rule IRuleReport) Recommendation {
+	critical := 0
+	for _, f := range rule.Findings {
+		if f.Severity == "critical" {
+			critical++
+		}
+	}
+
+	switch {
+	case rule.TCLErrors > 0:
+		return Recommendation{
+			Priority:    "high",
+			Title:       "iRule raising TCL errors",
+			Description: fmt.Sprintf("iRule %s raised %d TCL error(s); last: %s", rule.Name, rule.TCLErrors, rule.LastTCLError),
+			Impact:      r.formatAffectedVS(rule.VirtualServers),
+		}
+	case critical > 0:
+		return Recommendation{
+			Priority:    "high",
+			Title:       "iRule static analysis issues",
+			Description: fmt.Sprintf("iRule %s has %d critical finding(s), e.g. %s", rule.Name, critical, rule.Findings[0].Message),
+			Impact:      r.formatAffectedVS(rule.VirtualServers),
+		}
+	}
+	return Recommendation{}
+}
+
+// incidentRecommendation summarizes the incident windows, busiest first, so
+// the report says where to look instead of leaving it to the timeline.
+func (r *Recommender) incidentRecommendation(incidents []Incident) Recommendation {
+	if len(incidents) == 0 {
+		return Recommendation{}
+	}
+
+	sorted := append([]Incident(nil), incidents...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PeakRate > sorted[j].PeakRate
+	})
+
+	var windows []string
+	var affected []string
+	for i, incident := range sorted {
+		for _, vs := range incident.AffectedVS {
+			affected = appendUnique(affected, vs)
+		}
+		if i >= 3 {
+			continue
+		}
+		groups := make([]string, len(incident.DominantGroups))
+		for j, g := range incident.DominantGroups {
+			groups[j] = fmt.Sprintf("%s (%d)", g.Group, g.Count)
+		}
+		window := fmt.Sprintf("%s to %s, %s, peak %.2g lines/min", incident.Start, incident.End, strings.Join(incident.Trigger, " and "), incident.PeakRate)
+		if len(groups) > 0 {
+			window += ", mostly " + strings.Join(groups, ", ")
+		}
+		windows = append(windows, window)
+	}
+
+	priority := "medium"
+	for _, incident := range incidents {
+		if slices.Contains(incident.Trigger, "burst") {
+			priority = "high"
+		}
+	}
+
+	return Recommendation{
+		Priority:    priority,
+		Title:       "Investigate incident windows",
+		Description: fmt.Sprintf("%d incident window(s) detected: %s.", len(incidents), strings.Join(windows, "; ")),
+		Impact:      r.formatAffectedVS(affected),
+	}
+}
+
+// changeRecommendation points at the configuration changes that were
+// followed by an error spike, the first place to look after an outage.
+func (r *Recommender) changeRecommendation(changes []ConfigChange) Recommendation {
+	var suspects []string
+	for _, c := range changes {
+		if !c.ErrorSpike {
+			continue
+		}
+		what := c.Command
+		if len(c.Objects) > 0 {
+			what = c.Action + " " + strings.Join(c.Objects, ", ")
+		}
+		suspects = append(suspects, fmt.Sprintf("%s by %s via %s at %s (%d error lines in the hour before, %d after)",
+			truncate(what, 100), c.User, c.Interface, c.Time, c.ErrorsBefore, c.ErrorsAfter))
+	}
+	if len(suspects) == 0 {
+		return Recommendation{}
+	}
+
+	return Recommendation{
+		Priority:    "high",
+		Title:       "Errors rose after configuration change",
+		Description: fmt.Sprintf("%d change(s) were followed by an error spike: %s. Review them and roll back if they caused the errors.", len(suspects), strings.Join(suspects, "; ")),
+		Impact:      "Errors and warnings in the hour after the change",
+	}
+}
+
+// ruleRecommendations turns the findings of each user-defined rule into one
+// recommendation; the rule severity sets the priority.
+func (r *Recommender) ruleRecommendations(findings []RuleFinding) []Recommendation {
+	var order []string
+	byRule := make(map[string][]RuleFinding)
+	for _, f := range findings {
+		if _, ok := byRule[f.Rule]; !ok {
+			order = append(order, f.Rule)
+		}
+		byRule[f.Rule] = append(byRule[f.Rule], f)
+	}
+
+	var recommendations []Recommendation
+	for _, id := range order {
+		matches := byRule[id]
+		first := matches[0]
+		priority := map[string]string{
+			"critical": "critical",
+			"warning":  "medium",
+			"info":     "low",
+		}[first.Severity]
+
+		var where, affected []string
+		for _, f := range matches {
+			if f.Group != "" {
+				where = append(where, f.Group)
+			}
+			for _, vs := range f.AffectedVS {
+				affected = appendUnique(affected, vs)
+			}
+		}
+		sort.Strings(affected)
+
+		description := first.Description
+		if len(where) > 0 {
+			description = strings.TrimSpace(fmt.Sprintf("%s Matched for %s.", description, strings.Join(where, ", ")))
+		}
+		if first.Remediation != "" {
+			description = strings.TrimSpace(description + " " + first.Remediation)
+		}
+		recommendations = append(recommendations, Recommendation{
+			Priority:    priority,
+			Title:       first.Title,
+			Description: description,
+			Impact:      r.formatAffectedVS(affected),
+		})
+	}
+	return recommendations
+}
+
 func (r *Recommender) formatAffectedVS(vs []string) string {
 	if len(vs) == 0 {
 		return "Virtual servers affected: unknown"
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+	"goqkview/rules"
+)
+
+type RuleAnalyzer struct {
+	rules *rules.RuleSet
+}
+
+func NewRuleAnalyzer() *RuleAnalyzer {
+	return &RuleAnalyzer{rules: &rules.RuleSet{}}
+}
+
+// SetRules replaces the user-defined rules, e.g. with the result of
+// rules.Load.
+func (ra *RuleAnalyzer) SetRules(set *rules.RuleSet) {
+	if set == nil {
+		set = &rules.RuleSet{}
+	}
+	ra.rules = set
+}
+
+// Analyze evaluates the rules and reports each match with the virtual
+// servers behind the objects it names.
+func (ra *RuleAnalyzer) Analyze(entries []interfaces.LogEntry, config *parser.BigIPConfig) []RuleFinding {
+	findings := []RuleFinding{}
+	if ra.rules.Len() == 0 {
+		return findings
+	}
+
+	index := newVirtualServerIndex(config)
+	for _, match := range ra.rules.Evaluate(entries, config) {
+		rule := match.Rule
+		finding := RuleFinding{
+			Rule:        rule.ID,
+			Severity:    rule.Severity,
+			Title:       rule.Title,
+			Description: rule.Description,
+			Remediation: rule.Remediation,
+			Tags:        rule.Tags,
+			Group:       match.Group,
+			Count:       match.Count,
+			FirstSeen:   formatTime(match.First),
+			LastSeen:    formatTime(match.Last),
+			Objects:     []string{},
+			AffectedVS:  []string{},
+		}
+		if finding.Tags == nil {
+			finding.Tags = []string{}
+		}
+		for _, ref := range match.Objects {
+			name := ref.Path
+			if ref.Kind == parser.ObjectMember {
+				name = ref.Pool + "/" + ref.Name
+			}
+			finding.Objects = appendUnique(finding.Objects, name)
+			for _, vs := range index.lookup(ref) {
+				finding.AffectedVS = appendUnique(finding.AffectedVS, vs)
+			}
+		}
+		sort.Strings(finding.AffectedVS)
+		for _, entry := range match.Samples {
+			finding.Samples = append(finding.Samples, ErrorSample{
+				File:       logFile(entry.Path),
+				LineNumber: entry.LineNumber,
+				Line:       truncate(entry.Line, 500),
+			})
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// mergeRuleFindings adds the findings to the virtual servers they affect,
+// next to the built-in checks.
+func (ra *RuleAnalyzer) mergeRuleFindings(findings []RuleFinding, virtualServers []VirtualServerInfo) {
+	byPath := make(map[string]*VirtualServerInfo, len(virtualServers))
+	for i := range virtualServers {
+		byPath[virtualServers[i].Path] = &virtualServers[i]
+	}
+	for _, f := range findings {
+		message := f.Title
+		if f.Group != "" {
+			message += " (" + f.Group + ")"
+		}
+		for _, path := range f.AffectedVS {
+			if vs, ok := byPath[path]; ok {
+				vs.Findings = append(vs.Findings, VirtualServerFinding{
+					Severity: f.Severity,
+					Check:    f.Rule,
+					Message:  message,
+				})
+			}
+		}
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05Z")
+}
@@ -1,39 +1,54 @@
 package analyzer
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"goqkview/ciphers"
 	"goqkview/interfaces"
+	"goqkview/parser"
 )
 
 type SSLAnalyzer struct {
-	certExpiryPattern    *regexp.Regexp
-	tlsVersionPattern    *regexp.Regexp
-	cipherPattern        *regexp.Regexp
-	handshakePattern     *regexp.Regexp
-	virtualServerPattern *regexp.Regexp
+	certExpiryPattern *regexp.Regexp
+	certDatePattern   *regexp.Regexp
+	certDaysPattern   *regexp.Regexp
+	certNamePattern   *regexp.Regexp
+	tlsVersionPattern *regexp.Regexp
+	cipherPattern     *regexp.Regexp
+	handshakePattern  *regexp.Regexp
 }
 
 func NewSSLAnalyzer() *SSLAnalyzer {
 	return &SSLAnalyzer{
-		certExpiryPattern:    regexp.MustCompile(`(?i)certificate.*expir|cert.*expir|ssl.*expir|expir.*certificate`),
-		tlsVersionPattern:    regexp.MustCompile(`(?i)TLS\s*(1\.0|1\.1|1\.2|1\.3)|SSLv[23]`),
-		cipherPattern:        regexp.MustCompile(`(?i)cipher|RC4|DES|MD5|NULL|EXPORT|WEAK`),
-		handshakePattern:     regexp.MustCompile(`(?i)ssl\s*handshake|handshake\s*fail|certificate\s*verify`),
-		virtualServerPattern: regexp.MustCompile(`(?i)vs_[\w-]+|virtual[-_]?server[\s:]+(\S+)`),
+		certExpiryPattern: regexp.MustCompile(`(?i)certificate.*expir|cert.*expir|ssl.*expir|expir.*certificate`),
+		certDatePattern:   regexp.MustCompile(`(?i)(?:expire[sd]?(?:\s+on)?|notAfter\s*=)\s*:?\s*((?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}\s+\d{4})`),
+		certDaysPattern:   regexp.MustCompile(`(?i)expire\w*\s+in\s+(\d+)\s+days?`),
+		certNamePattern:   regexp.MustCompile(`(/[\w.-]+/[\w./-]+\.(?:crt|pem|cer))`),
+		tlsVersionPattern: regexp.MustCompile(`(?i)TLS\s*(1\.0|1\.1|1\.2|1\.3)|SSLv[23]`),
+		cipherPattern:     regexp.MustCompile(`(?i)cipher|RC4|DES|MD5|NULL|EXPORT|WEAK`),
+		handshakePattern:  regexp.MustCompile(`(?i)ssl\s*handshake|handshake\s*fail|certificate\s*verify`),
 	}
 }
 
-func (s *SSLAnalyzer) Analyze(entries []interfaces.LogEntry) []SSLFinding {
-	findings := []SSLFinding{}
+// Analyze reports certificate, protocol and cipher issues. Certificate
+// expiry is judged against reference, the time the qkview was taken; cipher
+// strings are expanded with the suite table of the device's BIG-IP version,
+// the newest one when the version is unknown.
+func (s *SSLAnalyzer) Analyze(entries []interfaces.LogEntry, config *parser.BigIPConfig, reference time.Time, version string) []SSLFinding {
+	table := ciphers.TableFor(version)
+	findings := s.analyzeProfiles(config, table)
 	seen := make(map[string]bool)
 
 	for _, entry := range entries {
-		line := entry.Line
+		line := messageText(entry)
 
 		if s.certExpiryPattern.MatchString(line) {
-			finding := s.analyzeCertIssue(entry)
+			finding := s.analyzeCertIssue(entry, reference)
 			key := finding.Type + finding.Message
 			if !seen[key] {
 				findings = append(findings, finding)
@@ -52,8 +67,8 @@ func (s *SSLAnalyzer) Analyze(entries []interfaces.LogEntry) []SSLFinding {
 			}
 		}
 
-		if s.cipherPattern.MatchString(line) && (entry.Status == "ERROR" || entry.Status == "WARNING" || entry.Status == "CRITICAL") {
-			finding := s.analyzeCipherIssue(entry)
+		if s.cipherPattern.MatchString(line) && (isErrorStatus(entry.Status) || entry.Status == "WARNING") {
+			finding := s.analyzeCipherIssue(entry, table)
 			if finding.Severity != "" {
 				key := finding.Type + finding.Message
 				if !seen[key] {
@@ -63,7 +78,7 @@ func (s *SSLAnalyzer) Analyze(entries []interfaces.LogEntry) []SSLFinding {
 			}
 		}
 
-		if s.handshakePattern.MatchString(line) && (entry.Status == "ERROR" || entry.Status == "CRITICAL") {
+		if s.handshakePattern.MatchString(line) && isErrorStatus(entry.Status) {
 			finding := s.analyzeHandshakeIssue(entry)
 			key := finding.Type + finding.Message
 			if !seen[key] {
@@ -76,29 +91,66 @@ func (s *SSLAnalyzer) Analyze(entries []interfaces.LogEntry) []SSLFinding {
 	return findings
 }
 
-func (s *SSLAnalyzer) analyzeCertIssue(entry interfaces.LogEntry) SSLFinding {
+func (s *SSLAnalyzer) analyzeCertIssue(entry interfaces.LogEntry, reference time.Time) SSLFinding {
 	finding := SSLFinding{
 		Type:       "certificate",
-		AffectedVS: s.extractVirtualServers(entry.Line),
+		AffectedVS: s.extractVirtualServers(entry),
+		Detail:     s.extractDetail(messageText(entry)),
+	}
+
+	cert := "Certificate"
+	if m := s.certNamePattern.FindStringSubmatch(entry.Line); m != nil {
+		cert = "Certificate " + m[1]
+	}
+
+	expiry, ok := s.certExpiry(entry)
+	if !ok {
+		finding.Severity = "warning"
+		if isErrorStatus(entry.Status) {
+			finding.Severity = "critical"
+		}
+		finding.Message = "Certificate expiration detected"
+		return finding
 	}
 
-	switch entry.Status {
-	case "CRITICAL", "SEVERE", "ERROR":
+	days := int(expiry.Sub(reference).Hours() / 24)
+	switch {
+	case !expiry.After(reference):
 		finding.Severity = "critical"
-	default:
+		finding.Message = fmt.Sprintf("%s expired on %s", cert, expiry.Format("2006-01-02"))
+	case days <= certExpiryWarningDays:
 		finding.Severity = "warning"
+		finding.Message = fmt.Sprintf("%s expires on %s, %d day(s) after capture", cert, expiry.Format("2006-01-02"), days)
+	default:
+		finding.Severity = "info"
+		finding.Message = fmt.Sprintf("%s expires on %s", cert, expiry.Format("2006-01-02"))
 	}
+	return finding
+}
 
-	finding.Message = "Certificate expiration detected"
-	finding.Detail = s.extractDetail(entry.Line)
+// Certificates expiring this many days after the reference time are flagged.
+const certExpiryWarningDays = 30
 
-	return finding
+// certExpiry reads the expiry date from lines such as "expires on Jan 1
+// 00:00:00 2025 GMT" or "will expire in 12 days".
+func (s *SSLAnalyzer) certExpiry(entry interfaces.LogEntry) (time.Time, bool) {
+	if m := s.certDatePattern.FindStringSubmatch(entry.Line); m != nil {
+		if t, err := time.Parse("Jan 2 15:04:05 2006", strings.Join(strings.Fields(m[1]), " ")); err == nil {
+			return t, true
+		}
+	}
+	if m := s.certDaysPattern.FindStringSubmatch(entry.Line); m != nil && !entry.Timestamp.IsZero() {
+		if days, err := strconv.Atoi(m[1]); err == nil {
+			return entry.Timestamp.AddDate(0, 0, days), true
+		}
+	}
+	return time.Time{}, false
 }
 
 func (s *SSLAnalyzer) analyzeTLSVersion(entry interfaces.LogEntry) SSLFinding {
 	finding := SSLFinding{
 		Type:       "cipher",
-		AffectedVS: s.extractVirtualServers(entry.Line),
+		AffectedVS: s.extractVirtualServers(entry),
 	}
 
 	line := strings.ToLower(entry.Line)
@@ -117,25 +169,107 @@ func (s *SSLAnalyzer) analyzeTLSVersion(entry interfaces.LogEntry) SSLFinding {
 	return finding
 }
 
-func (s *SSLAnalyzer) analyzeCipherIssue(entry interfaces.LogEntry) SSLFinding {
+func (s *SSLAnalyzer) analyzeCipherIssue(entry interfaces.LogEntry, table *ciphers.Table) SSLFinding {
 	finding := SSLFinding{
 		Type:       "cipher",
-		AffectedVS: s.extractVirtualServers(entry.Line),
+		AffectedVS: s.extractVirtualServers(entry),
 	}
 
-	line := strings.ToLower(entry.Line)
+	insecure, weak := s.gradeSuites(table.FindInText(entry.Line))
+	switch {
+	case len(insecure) > 0:
+		finding.Severity = "critical"
+		finding.Message = "Insecure cipher suite negotiated"
+		finding.Suites = insecure
+	case len(weak) > 0:
+		finding.Severity = "warning"
+		finding.Message = "Weak cipher suite negotiated"
+		finding.Suites = weak
+	default:
+		return finding
+	}
+	finding.Detail = "Cipher suites: " + strings.Join(finding.Suites, ", ")
 
-	weakCiphers := []string{"rc4", "des", "null", "export", "md5"}
-	for _, cipher := range weakCiphers {
-		if strings.Contains(line, cipher) {
-			finding.Severity = "critical"
-			finding.Message = "Weak cipher suite detected"
-			finding.Detail = "Cipher contains: " + strings.ToUpper(cipher)
-			break
+	return finding
+}
+
+// analyzeProfiles expands the effective cipher string of every client-ssl
+// profile and reports the exact insecure and weak suites it enables.
+func (s *SSLAnalyzer) analyzeProfiles(config *parser.BigIPConfig, table *ciphers.Table) []SSLFinding {
+	findings := []SSLFinding{}
+	if config == nil {
+		return findings
+	}
+
+	for _, path := range sortedKeys(config.ByPath.ClientSSLProfiles) {
+		cipherString := config.EffectiveCiphers(path)
+		expansion := table.Expand(cipherString)
+		affected := s.virtualServersWithProfile(config, path)
+
+		if len(expansion.Unknown) > 0 {
+			findings = append(findings, SSLFinding{
+				Severity:   "info",
+				Type:       "configuration",
+				Message:    fmt.Sprintf("Unrecognized cipher rules in client-ssl profile %s", path),
+				Detail:     "Ignored tokens: " + strings.Join(expansion.Unknown, ", "),
+				AffectedVS: affected,
+				Profile:    path,
+			})
+		}
+
+		insecure, weak := s.gradeSuites(expansion.Suites)
+		if len(insecure) > 0 {
+			findings = append(findings, SSLFinding{
+				Severity:   "critical",
+				Type:       "cipher",
+				Message:    fmt.Sprintf("Insecure cipher suites enabled in client-ssl profile %s", path),
+				Detail:     fmt.Sprintf("Cipher string %q enables %d insecure suite(s)", cipherString, len(insecure)),
+				AffectedVS: affected,
+				Profile:    path,
+				Suites:     insecure,
+			})
+		}
+		if len(weak) > 0 {
+			findings = append(findings, SSLFinding{
+				Severity:   "warning",
+				Type:       "cipher",
+				Message:    fmt.Sprintf("Weak cipher suites enabled in client-ssl profile %s", path),
+				Detail:     fmt.Sprintf("Cipher string %q enables %d weak suite(s)", cipherString, len(weak)),
+				AffectedVS: affected,
+				Profile:    path,
+				Suites:     weak,
+			})
 		}
 	}
 
-	return finding
+	return findings
+}
+
+func (s *SSLAnalyzer) gradeSuites(suites []ciphers.Suite) (insecure, weak []string) {
+	for _, suite := range suites {
+		strength, reason := ciphers.Classify(suite)
+		switch strength {
+		case ciphers.Insecure:
+			insecure = append(insecure, suite.Name+" ("+reason+")")
+		case ciphers.Weak:
+			weak = append(weak, suite.Name+" ("+reason+")")
+		}
+	}
+	return insecure, weak
+}
+
+func (s *SSLAnalyzer) virtualServersWithProfile(config *parser.BigIPConfig, profilePath string) []string {
+	vs := []string{}
+	for path, v := range config.ByPath.VirtualServers {
+		for _, p := range v.ProfilePaths {
+			if p == profilePath {
+				vs = append(vs, path)
+				break
+			}
+		}
+	}
+	sort.Strings(vs)
+	return vs
 }
 
 func (s *SSLAnalyzer) analyzeHandshakeIssue(entry interfaces.LogEntry) SSLFinding {
@@ -143,19 +277,18 @@ func (s *SSLAnalyzer) analyzeHandshakeIssue(entry interfaces.LogEntry) SSLFindin
 		Severity:   "warning",
 		Type:       "configuration",
 		Message:    "SSL handshake failure detected",
-		Detail:     s.extractDetail(entry.Line),
-		AffectedVS: s.extractVirtualServers(entry.Line),
+		Detail:     s.extractDetail(messageText(entry)),
+		AffectedVS: s.extractVirtualServers(entry),
 	}
 }
 
-func (s *SSLAnalyzer) extractVirtualServers(line string) []string {
-	matches := s.virtualServerPattern.FindAllStringSubmatch(line, -1)
+// extractVirtualServers lists the virtual servers the entry names, directly
+// or by destination.
+func (s *SSLAnalyzer) extractVirtualServers(entry interfaces.LogEntry) []string {
 	vs := []string{}
-	seen := make(map[string]bool)
-	for _, match := range matches {
-		if len(match) > 0 && !seen[match[0]] {
-			vs = append(vs, match[0])
-			seen[match[0]] = true
+	for _, ref := range entry.Objects {
+		if ref.Kind == parser.ObjectVirtual {
+			vs = appendUnique(vs, ref.Path)
 		}
 	}
 	return vs
@@ -0,0 +1,202 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Template mining in the manner of Drain (He et al., "Drain: An Online Log
+// Parsing Approach with Fixed Depth Tree", ICWS 2017). A message is split
+// into tokens, obvious variables are masked, and the message is routed by
+// its token count and leading tokens to a leaf whose most similar template
+// absorbs it; tokens where the two differ become <*> slots.
+const (
+	templateDepth       = 2   // Leading tokens used for routing
+	templateSimilarity  = 0.5 // Share of equal tokens needed to join a template
+	templateMaxChildren = 100 // Routing fan-out before further tokens share the <*> branch
+	templateWildcard    = "<*>"
+)
+
+// templateMasks replace variables the tree would otherwise have to learn,
+// applied in order to each token.
+var templateMasks = []struct {
+	placeholder string
+	pattern     *regexp.Regexp
+}{
+	{"<UUID>", regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)},
+	{"<DATE>", regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)},
+	{"<TIME>", regexp.MustCompile(`\b\d{1,2}:\d{2}:\d{2}(?:[.,]\d+)?\b`)},
+	{"<IP>", regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?:%\d+)?(?:[:.]\d+)?\b`)},
+	{"<IP>", regexp.MustCompile(`\b(?:(?:[0-9a-fA-F]{1,4}:){3,7}|(?:[0-9a-fA-F]{1,4}:)+:)[0-9a-fA-F]{1,4}(?:%\d+)?(?:\.\d+)?\b`)},
+	{"<HEX>", regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{8,}\b`)},
+	{"<NUM>", regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)},
+}
+
+type templateMiner struct {
+	roots map[int]*templateNode // By token count
+}
+
+type templateNode struct {
+	children map[string]*templateNode
+	clusters []*templateCluster
+}
+
+type templateCluster struct {
+	tokens []string
+	count  int
+}
+
+func newTemplateMiner() *templateMiner {
+	return &templateMiner{roots: make(map[int]*templateNode)}
+}
+
+// messageTokens is a message split at whitespace: the tokens as written,
+// the same with variables masked, and the masked values of each token.
+type messageTokens struct {
+	raw    []string
+	masked []string
+	values [][]string
+}
+
+func tokenize(message string) messageTokens {
+	raw := strings.Fields(message)
+	t := messageTokens{raw: raw, masked: make([]string, len(raw)), values: make([][]string, len(raw))}
+	for i, token := range raw {
+		for _, mask := range templateMasks {
+			token = mask.pattern.ReplaceAllStringFunc(token, func(value string) string {
+				t.values[i] = append(t.values[i], value)
+				return mask.placeholder
+			})
+		}
+		t.masked[i] = token
+
+		// Masks run one after the other; report values in reading order.
+		sort.SliceStable(t.values[i], func(a, b int) bool {
+			return strings.Index(raw[i], t.values[i][a]) < strings.Index(raw[i], t.values[i][b])
+		})
+	}
+	return t
+}
+
+// isVariable is true for tokens that must not steer routing: slots, masked
+// values, numbers and object paths such as /Common/app_pool.
+func isVariable(token string) bool {
+	return token == templateWildcard || strings.ContainsAny(token, "0123456789</")
+}
+
+// leaf walks to the leaf for tokens, creating nodes when create is set.
+func (m *templateMiner) leaf(tokens []string, create bool) *templateNode {
+	node, ok := m.roots[len(tokens)]
+	if !ok {
+		if !create {
+			return nil
+		}
+		node = &templateNode{children: make(map[string]*templateNode)}
+		m.roots[len(tokens)] = node
+	}
+
+	for i := 0; i < templateDepth && i < len(tokens); i++ {
+		key := tokens[i]
+		if isVariable(key) {
+			key = templateWildcard
+		}
+		child, ok := node.children[key]
+		if !ok && key != templateWildcard && len(node.children) >= templateMaxChildren {
+			key = templateWildcard
+			child, ok = node.children[key]
+		}
+		if !ok {
+			if !create {
+				return nil
+			}
+			child = &templateNode{children: make(map[string]*templateNode)}
+			node.children[key] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// add places the masked tokens of a message in the most similar cluster of
+// its leaf, generalizing that template, or starts a new cluster.
+func (m *templateMiner) add(tokens []string) *templateCluster {
+	node := m.leaf(tokens, true)
+	if best := bestCluster(node.clusters, tokens, false); best != nil {
+		for i, token := range tokens {
+			if best.tokens[i] != token {
+				best.tokens[i] = templateWildcard
+			}
+		}
+		best.count++
+		return best
+	}
+
+	cluster := &templateCluster{tokens: append([]string(nil), tokens...), count: 1}
+	node.clusters = append(node.clusters, cluster)
+	return cluster
+}
+
+// match finds the cluster a message belongs to without changing the miner.
+// A message no cluster fits gets a cluster of its own that is not stored.
+func (m *templateMiner) match(tokens []string) *templateCluster {
+	if node := m.leaf(tokens, false); node != nil {
+		if best := bestCluster(node.clusters, tokens, true); best != nil {
+			return best
+		}
+	}
+	return &templateCluster{tokens: append([]string(nil), tokens...)}
+}
+
+// bestCluster returns the cluster with the highest share of equal tokens, if
+// that reaches templateSimilarity. Slots count as equal when wildcards is set.
+func bestCluster(clusters []*templateCluster, tokens []string, wildcards bool) *templateCluster {
+	var best *templateCluster
+	bestScore, bestSlots := -1.0, -1
+	for _, cluster := range clusters {
+		equal, slots := 0, 0
+		for i, token := range cluster.tokens {
+			switch {
+			case token == templateWildcard:
+				slots++
+				if wildcards {
+					equal++
+				}
+			case token == tokens[i]:
+				equal++
+			}
+		}
+		score := 1.0
+		if len(tokens) > 0 {
+			score = float64(equal) / float64(len(tokens))
+		}
+		if score > bestScore || (score == bestScore && slots > bestSlots) {
+			best, bestScore, bestSlots = cluster, score, slots
+		}
+	}
+	if bestScore < templateSimilarity {
+		return nil
+	}
+	return best
+}
+
+func (c *templateCluster) template() string {
+	return strings.Join(c.tokens, " ")
+}
+
+// params returns the values of a message in the template's variable parts:
+// whole tokens for <*> slots, the masked values elsewhere.
+func (c *templateCluster) params(t messageTokens) []string {
+	params := []string{}
+	for i, token := range c.tokens {
+		if i >= len(t.raw) {
+			break
+		}
+		if token == templateWildcard {
+			params = append(params, t.raw[i])
+		} else {
+			params = append(params, t.values[i]...)
+		}
+	}
+	return params
+}
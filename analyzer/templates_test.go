@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeMasks(t *testing.T) {
+	tests := []struct {
+		message string
+		masked  string
+		values  [][]string
+	}{
+		{
+			"Pool /Common/app member 10.0.0.5:80 monitor status down",
+			"Pool /Common/app member <IP> monitor status down",
+			[][]string{nil, nil, nil, {"10.0.0.5:80"}, nil, nil, nil},
+		},
+		{
+			"connection 2001:db8::1.443 reset after 30.5 seconds",
+			"connection <IP> reset after <NUM> seconds",
+			[][]string{nil, {"2001:db8::1.443"}, nil, nil, {"30.5"}, nil},
+		},
+		{
+			"flow 0xdeadbeef at 2024-01-02 03:04:05.123",
+			"flow <HEX> at <DATE> <TIME>",
+			[][]string{nil, {"0xdeadbeef"}, nil, {"2024-01-02"}, {"03:04:05.123"}},
+		},
+		{
+			"session 123e4567-e89b-12d3-a456-426614174000 closed",
+			"session <UUID> closed",
+			[][]string{nil, {"123e4567-e89b-12d3-a456-426614174000"}, nil},
+		},
+	}
+	for _, tt := range tests {
+		got := tokenize(tt.message)
+		if masked := strings.Join(got.masked, " "); masked != tt.masked {
+			t.Errorf("tokenize(%q) masked = %q, want %q", tt.message, masked, tt.masked)
+		}
+		if !reflect.DeepEqual(got.values, tt.values) {
+			t.Errorf("tokenize(%q) values = %q, want %q", tt.message, got.values, tt.values)
+		}
+	}
+}
+
+func TestTemplateMiner(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []string
+		templates []string // Clusters in the order they were started
+		params    []string // Of the last message in its cluster
+	}{
+		{
+			name: "differing tokens become slots",
+			messages: []string{
+				"Pool /Common/web member 10.0.0.5:80 monitor status down",
+				"Pool /Common/api member 10.0.0.6:8080 monitor status down",
+			},
+			templates: []string{"Pool <*> member <IP> monitor status down"},
+			params:    []string{"/Common/api", "10.0.0.6:8080"},
+		},
+		{
+			name: "dissimilar messages stay apart",
+			messages: []string{
+				"disk usage high on /var partition",
+				"disk usage check skipped by operator",
+				"disk usage high on /shared partition",
+			},
+			templates: []string{"disk usage high on <*> partition", "disk usage check skipped by operator"},
+			params:    []string{"/shared"},
+		},
+		{
+			name: "leading tokens and token count route",
+			messages: []string{
+				"link 1.1 is down",
+				"link 1.1 is down again",
+				"admin link 1.1 is down",
+			},
+			templates: []string{"link <NUM> is down", "link <NUM> is down again", "admin link <NUM> is down"},
+			params:    []string{"1.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTemplateMiner()
+			var clusters []*templateCluster
+			var last *templateCluster
+			var lastTokens messageTokens
+			for _, message := range tt.messages {
+				lastTokens = tokenize(message)
+				last = m.add(lastTokens.masked)
+				found := false
+				for _, c := range clusters {
+					found = found || c == last
+				}
+				if !found {
+					clusters = append(clusters, last)
+				}
+			}
+
+			var templates []string
+			for _, c := range clusters {
+				templates = append(templates, c.template())
+			}
+			if !reflect.DeepEqual(templates, tt.templates) {
+				t.Errorf("templates = %q, want %q", templates, tt.templates)
+			}
+			if params := last.params(lastTokens); !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("params = %q, want %q", params, tt.params)
+			}
+
+			// match finds the same cluster without generalizing it further.
+			before := last.template()
+			if got := m.match(lastTokens.masked); got != last || got.template() != before {
+				t.Errorf("match returned %q, want %q", got.template(), before)
+			}
+		})
+	}
+}
+
+func TestTemplateMinerMatchMiss(t *testing.T) {
+	m := newTemplateMiner()
+	m.add(tokenize("link 1.1 is down on external").masked)
+
+	// A new token count, a new leaf, and too few equal tokens in a leaf.
+	for _, message := range []string{"link 1.1 is down", "disk full on /var now", "link 1.2 removed by the admin"} {
+		tokens := tokenize(message).masked
+		got := m.match(tokens)
+		if want := strings.Join(tokens, " "); got == nil || got.template() != want {
+			t.Errorf("match(%q) = %v, want a cluster of its own", message, got)
+		}
+		if m.match(tokens) == got {
+			t.Errorf("match(%q) stored the cluster", message)
+		}
+	}
+	if len(m.roots) != 1 {
+		t.Errorf("match added %d token counts to the miner", len(m.roots)-1)
+	}
+	if node := m.leaf(tokenize("link 1.2 removed by the admin").masked, false); node == nil || len(node.clusters) != 1 {
+		t.Error("match added a cluster to an existing leaf")
+	}
+}
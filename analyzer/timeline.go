@@ -1,41 +1,170 @@
 package analyzer
 
 import (
+	"fmt"
 	"sort"
+	"time"
 
 	"goqkview/interfaces"
 )
 
-type TimelineBuilder struct{}
+// Timeline resolutions. Auto picks the bucket size from the time span the
+// timeline covers.
+const (
+	ResolutionAuto   = "auto"
+	ResolutionMinute = "minute"
+	ResolutionHour   = "hour"
+	ResolutionDay    = "day"
+)
+
+// Spans up to these lengths are bucketed by minute and by hour under auto,
+// longer ones by day.
+const (
+	autoMinuteSpan = 6 * time.Hour
+	autoHourSpan   = 7 * 24 * time.Hour
+)
 
-func NewTimelineBuilder() *TimelineBuilder {
-	return &TimelineBuilder{}
+type TimelineBuilder struct {
+	resolution string
+	errors     *ErrorAnalyzer
 }
 
-func (t *TimelineBuilder) Build(entries []interfaces.LogEntry) []TimelineEntry {
-	dateCounts := make(map[string]int)
+// NewTimelineBuilder creates a builder that buckets errors into the groups
+// of errors.
+func NewTimelineBuilder(errors *ErrorAnalyzer) *TimelineBuilder {
+	return &TimelineBuilder{resolution: ResolutionAuto, errors: errors}
+}
 
-	for _, entry := range entries {
-		if entry.Status == "ERROR" || entry.Status == "CRITICAL" ||
-			entry.Status == "SEVERE" || entry.Status == "WARNING" {
-			date := entry.Timestamp.Format("2006-01-02")
-			if date != "0001-01-01" {
-				dateCounts[date]++
+// SetResolution sets the bucket size: minute, hour, day or auto. Empty
+// means auto.
+func (t *TimelineBuilder) SetResolution(resolution string) error {
+	switch resolution {
+	case "":
+		t.resolution = ResolutionAuto
+		return nil
+	case ResolutionAuto, ResolutionMinute, ResolutionHour, ResolutionDay:
+		t.resolution = resolution
+		return nil
+	}
+	return fmt.Errorf("analyzer: unknown timeline resolution %q (want minute, hour, day or auto)", resolution)
+}
+
+// Build buckets the entries and changes and returns the buckets with at
+// least one error, warning or change, oldest first, and the resolution used.
+func (t *TimelineBuilder) Build(entries []interfaces.LogEntry, changes []ConfigChange) ([]TimelineEntry, string) {
+	resolution := t.resolution
+	if resolution == ResolutionAuto {
+		resolution = t.autoResolution(entries, changes)
+	}
+
+	buckets := make(map[time.Time]*TimelineEntry)
+	bucket := func(ts time.Time) *TimelineEntry {
+		start := bucketStart(ts, resolution)
+		b, ok := buckets[start]
+		if !ok {
+			b = &TimelineEntry{
+				Date:       bucketLabel(start, resolution),
+				Severities: make(map[string]int),
+				Facilities: make(map[string]int),
+				Groups:     make(map[string]int),
 			}
+			buckets[start] = b
 		}
+		return b
 	}
 
-	timeline := make([]TimelineEntry, 0, len(dateCounts))
-	for date, count := range dateCounts {
-		timeline = append(timeline, TimelineEntry{
-			Date:   date,
-			Errors: count,
-		})
+	for _, entry := range entries {
+		if entry.Timestamp.IsZero() || !isTimelineStatus(entry.Status) {
+			continue
+		}
+		b := bucket(entry.Timestamp)
+		if entry.Status == "WARNING" {
+			b.Warnings++
+		} else {
+			b.Errors++
+			b.Groups[t.errors.groupLabel(entry)]++
+		}
+		b.Facilities[entry.Facility]++
+	}
+	for _, change := range changes {
+		bucket(change.time).Changes++
+	}
+
+	// Buckets exist now; fill in the full severity breakdown.
+	for _, entry := range entries {
+		if entry.Timestamp.IsZero() {
+			continue
+		}
+		if b, ok := buckets[bucketStart(entry.Timestamp, resolution)]; ok {
+			b.Severities[entry.Status]++
+		}
 	}
 
-	sort.Slice(timeline, func(i, j int) bool {
-		return timeline[i].Date < timeline[j].Date
+	starts := make([]time.Time, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool {
+		return starts[i].Before(starts[j])
 	})
 
-	return timeline
+	timeline := make([]TimelineEntry, len(starts))
+	for i, start := range starts {
+		timeline[i] = *buckets[start]
+	}
+	return timeline, resolution
+}
+
+// autoResolution picks the finest bucket size that keeps the timeline to a
+// few hundred buckets.
+func (t *TimelineBuilder) autoResolution(entries []interfaces.LogEntry, changes []ConfigChange) string {
+	var first, last time.Time
+	see := func(ts time.Time) {
+		if first.IsZero() || ts.Before(first) {
+			first = ts
+		}
+		if ts.After(last) {
+			last = ts
+		}
+	}
+	for _, entry := range entries {
+		if !entry.Timestamp.IsZero() && isTimelineStatus(entry.Status) {
+			see(entry.Timestamp)
+		}
+	}
+	for _, change := range changes {
+		see(change.time)
+	}
+
+	switch span := last.Sub(first); {
+	case span <= autoMinuteSpan:
+		return ResolutionMinute
+	case span <= autoHourSpan:
+		return ResolutionHour
+	}
+	return ResolutionDay
+}
+
+func bucketStart(ts time.Time, resolution string) time.Time {
+	ts = ts.UTC()
+	switch resolution {
+	case ResolutionMinute:
+		return ts.Truncate(time.Minute)
+	case ResolutionHour:
+		return ts.Truncate(time.Hour)
+	}
+	return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func bucketLabel(start time.Time, resolution string) string {
+	if resolution == ResolutionDay {
+		return start.Format("2006-01-02")
+	}
+	return start.Format("2006-01-02T15:04:05Z")
+}
+
+// isTimelineStatus is true for the entries the timeline is built from:
+// errors and warnings.
+func isTimelineStatus(status string) bool {
+	return isErrorStatus(status) || status == "WARNING"
 }
@@ -3,19 +3,55 @@ package analyzer
 import "time"
 
 type AnalysisResult struct {
-	Summary         Summary             `json:"summary"`
-	ErrorTimeline   []TimelineEntry     `json:"errorTimeline"`
-	SSLFindings     []SSLFinding        `json:"sslFindings"`
-	TopErrors       []TopError          `json:"topErrors"`
-	Recommendations []Recommendation    `json:"recommendations"`
-	VirtualServers  []VirtualServerInfo `json:"virtualServers"`
-	EntryLogs       []EntryLog          `json:"entryLogs"`
+	Device              *Device             `json:"device"`
+	ReferenceTime       string              `json:"referenceTime"`       // ISO8601, capture time of the qkview
+	ReferenceTimeSource string              `json:"referenceTimeSource"` // qkview, logs, now
+	Summary             Summary             `json:"summary"`
+	TimelineResolution  string              `json:"timelineResolution"` // minute, hour or day
+	ErrorTimeline       []TimelineEntry     `json:"errorTimeline"`
+	Incidents           []Incident          `json:"incidents"`
+	ConfigChanges       []ConfigChange      `json:"configChanges"`
+	SSLFindings         []SSLFinding        `json:"sslFindings"`
+	RuleFindings        []RuleFinding       `json:"ruleFindings"`
+	TopErrors           []TopError          `json:"topErrors"`
+	Recommendations     []Recommendation    `json:"recommendations"`
+	VirtualServers      []VirtualServerInfo `json:"virtualServers"`
+	MemberHistory       []MemberHistory     `json:"memberHistory"`
+	Nodes               []NodeHealth        `json:"nodes"`
+	IRules              []IRuleReport       `json:"iRules"`
+	ConfigHygiene       ConfigHygiene       `json:"configHygiene"`
+	EntryLogs           []EntryLog          `json:"entryLogs"`
+}
+
+type Device struct {
+	Hostname      string   `json:"hostname"`
+	Product       string   `json:"product,omitempty"`
+	Version       string   `json:"version"`
+	Build         string   `json:"build,omitempty"`
+	Edition       string   `json:"edition,omitempty"`
+	Hotfix        string   `json:"hotfix,omitempty"`
+	PlatformID    string   `json:"platformId,omitempty"`
+	MarketingName string   `json:"marketingName,omitempty"`
+	Serial        string   `json:"serial,omitempty"`
+	ManagementIP  string   `json:"managementIp,omitempty"`
+	TimeZone      string   `json:"timeZone,omitempty"`
+	Modules       []string `json:"modules"` // Provisioned modules, e.g. "ltm (nominal)"
+	HARole        string   `json:"haRole,omitempty"`
+	FailoverState string   `json:"failoverState,omitempty"`
+	SyncStatus    string   `json:"syncStatus,omitempty"`
+	UptimeSeconds int64    `json:"uptimeSeconds,omitempty"`
+	GeneratedAt   string   `json:"generatedAt,omitempty"` // ISO8601 format
 }
 
 type EntryLog struct {
-	Message string `json:"message"`
-	Level   string `json:"level"`
-	Date    string `json:"date"` // ISO8601 format
+	Message    string   `json:"message"`
+	Body       string   `json:"body,omitempty"` // Continuation lines of a multi-line entry
+	Level      string   `json:"level"`
+	LevelFrom  string   `json:"levelFrom,omitempty"`  // syslog, message-id, header or keyword
+	Date       string   `json:"date"`                 // ISO8601 format, UTC
+	UTCOffset  string   `json:"utcOffset,omitempty"`  // Offset the line was written in
+	OutOfOrder bool     `json:"outOfOrder,omitempty"` // Earlier than the line before it in the same file
+	Objects    []string `json:"objects,omitempty"`    // Full paths of the configuration objects the line names
 }
 
 type Summary struct {
@@ -26,22 +62,109 @@ type Summary struct {
 }
 
 type TimelineEntry struct {
-	Date   string `json:"date"` // YYYY-MM-DD format
-	Errors int    `json:"errors"`
+	Date       string         `json:"date"`   // Bucket start: YYYY-MM-DD for days, ISO8601 UTC for hours and minutes
+	Errors     int            `json:"errors"` // EMERGENCY, ALERT, CRITICAL, ERROR and SEVERE
+	Warnings   int            `json:"warnings"`
+	Changes    int            `json:"changes,omitempty"` // Configuration changes from the audit log
+	Severities map[string]int `json:"severities"`        // All entries in the bucket by status
+	Facilities map[string]int `json:"facilities"`        // Errors and warnings by facility
+	Groups     map[string]int `json:"groups"`            // Errors by group: message ID or template
+}
+
+type Incident struct {
+	Start          string       `json:"start"`     // ISO8601 format, UTC
+	End            string       `json:"end"`       // End of the last anomalous bucket
+	Trigger        []string     `json:"trigger"`   // burst, new-error-group
+	Lines          int          `json:"lines"`     // Error and warning lines in the window
+	Baseline       float64      `json:"baseline"`  // Median lines per bucket before the window
+	PeakLines      int          `json:"peakLines"` // Lines in the busiest bucket
+	PeakRate       float64      `json:"peakRate"`  // The same per minute
+	Peak           string       `json:"peak"`      // Start of the busiest bucket
+	DominantGroups []GroupCount `json:"dominantGroups"`
+	NewGroups      []string     `json:"newGroups"`  // Error groups first seen in the window
+	AffectedVS     []string     `json:"affectedVS"` // Full paths
+
+	start, end time.Time
+}
+
+type GroupCount struct {
+	Group string `json:"group"` // Message ID or template
+	Count int    `json:"count"`
+}
+
+type ConfigChange struct {
+	Time         string   `json:"time"` // ISO8601 format, UTC
+	User         string   `json:"user"`
+	Address      string   `json:"address,omitempty"` // Logged, or the user's latest login
+	Interface    string   `json:"interface"`         // tmsh, iControl REST, GUI, ...
+	Action       string   `json:"action"`
+	Objects      []string `json:"objects"`
+	Command      string   `json:"command"`
+	Result       string   `json:"result,omitempty"`
+	Success      bool     `json:"success"`
+	ErrorsBefore int      `json:"errorsBefore"` // Error and warning lines in the hour before
+	ErrorsAfter  int      `json:"errorsAfter"`  // ... and in the hour after
+	ErrorSpike   bool     `json:"errorSpike"`
+
+	time time.Time
 }
 
 type SSLFinding struct {
-	Severity   string   `json:"severity"`   // critical, warning, info
-	Type       string   `json:"type"`       // certificate, cipher, configuration
+	Severity   string   `json:"severity"` // critical, warning, info
+	Type       string   `json:"type"`     // certificate, cipher, configuration
 	Message    string   `json:"message"`
 	Detail     string   `json:"detail"`
-	AffectedVS []string `json:"affectedVS"` // Virtual servers affected
+	AffectedVS []string `json:"affectedVS"`        // Full paths of the virtual servers affected
+	Profile    string   `json:"profile,omitempty"` // client-ssl profile, for config findings
+	Suites     []string `json:"suites,omitempty"`  // Offending cipher suites with reason
 }
 
 type TopError struct {
-	Message      string    `json:"message"`
-	Count        int       `json:"count"`
-	LastOccurred time.Time `json:"-"` // Used internally, serialized differently
+	Message      string     `json:"message"`
+	Count        int        `json:"count"`
+	LastOccurred time.Time  `json:"-"`                      // Used internally, serialized differently
+	Template     string     `json:"template"`               // Message with variable parts as <*>, <IP>, <NUM>, ...
+	SampleParams [][]string `json:"sampleParams,omitempty"` // Values of those parts in a few lines
+
+	// Context for triage
+	FirstOccurred  time.Time     `json:"-"`
+	RatePerHour    float64       `json:"ratePerHour"` // Between the first and last occurrence, at least an hour
+	Files          []string      `json:"files"`       // Log files inside the qkview, e.g. var/log/ltm
+	Hosts          []string      `json:"hosts"`
+	VirtualServers []string      `json:"virtualServers"` // Full paths of the objects the lines name
+	Pools          []string      `json:"pools"`
+	Members        []string      `json:"members"` // pool path/member
+	Samples        []ErrorSample `json:"samples"` // Earliest lines of the group
+
+	// Set when the lines carry a BIG-IP message ID; known IDs add the catalog entry
+	MessageID string `json:"messageId,omitempty"`
+	Component string `json:"component,omitempty"`
+	Meaning   string `json:"meaning,omitempty"`
+	Severity  string `json:"severity,omitempty"` // critical, error, warning, info
+	Action    string `json:"action,omitempty"`
+}
+
+type ErrorSample struct {
+	File       string `json:"file"`
+	LineNumber int    `json:"lineNumber,omitempty"` // 1-based; absent for journal entries
+	Line       string `json:"line"`
+}
+
+// RuleFinding is a match of a user-defined rule.
+type RuleFinding struct {
+	Rule        string        `json:"rule"`
+	Severity    string        `json:"severity"` // critical, warning, info
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Remediation string        `json:"remediation,omitempty"`
+	Tags        []string      `json:"tags"`
+	Group       string        `json:"group,omitempty"`     // Object, file or host the lines were counted for
+	Count       int           `json:"count,omitempty"`     // Matching lines in the window
+	FirstSeen   string        `json:"firstSeen,omitempty"` // ISO8601, first matching line in the window
+	LastSeen    string        `json:"lastSeen,omitempty"`
+	Objects     []string      `json:"objects"` // Full paths, pool/member for members
+	AffectedVS  []string      `json:"affectedVS"`
+	Samples     []ErrorSample `json:"samples,omitempty"`
 }
 
 type Recommendation struct {
@@ -50,3 +173,80 @@ type Recommendation struct {
 	Description string `json:"description"`
 	Impact      string `json:"impact"`
 }
+
+type MemberStateChange struct {
+	Time  time.Time `json:"time"`
+	State string    `json:"state"` // up, down, unchecked, forced-offline
+}
+
+type MemberHistory struct {
+	Pool                 string              `json:"pool"` // Full path
+	Member               string              `json:"member"`
+	Transitions          []MemberStateChange `json:"transitions"`
+	FlapCount            int                 `json:"flapCount"` // Transitions into down
+	DowntimeSeconds      int64               `json:"downtimeSeconds"`
+	LongestOutageSeconds int64               `json:"longestOutageSeconds"`
+	CurrentState         string              `json:"currentState"`
+	Summary              string              `json:"summary"`
+}
+
+type NodeHealth struct {
+	Name               string   `json:"name"`
+	Path               string   `json:"path"` // Full path, e.g. /Common/10.0.0.5
+	Address            string   `json:"address"`
+	Monitor            string   `json:"monitor,omitempty"`
+	State              string   `json:"state"` // up, degraded, down, disabled
+	Reason             string   `json:"reason,omitempty"`
+	Pools              []string `json:"pools"`          // Full paths
+	VirtualServers     []string `json:"virtualServers"` // Full paths
+	OfflineVS          []string `json:"offlineVirtualServers,omitempty"`
+	TotalMembers       int      `json:"totalMembers"`
+	UnavailableMembers int      `json:"unavailableMembers"`
+	LogEvents          int      `json:"logEvents"`   // Log lines naming the node or one of its members
+	ErrorEvents        int      `json:"errorEvents"` // Of those, error-level lines
+	LastEvent          string   `json:"lastEvent,omitempty"`
+	Impact             string   `json:"impact,omitempty"` // multi-service when shared by several virtuals
+	Finding            string   `json:"finding,omitempty"`
+
+	lastEvent      time.Time
+	lastTransition time.Time
+	monitorState   string
+}
+
+type IRuleFinding struct {
+	Severity string `json:"severity"` // critical, warning
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+	Line     int    `json:"line"` // 1-based line within the rule body
+	Event    string `json:"event,omitempty"`
+}
+
+type IRuleReport struct {
+	Name           string         `json:"name"`
+	Path           string         `json:"path"`           // Full path, e.g. /Common/rule_redirect
+	VirtualServers []string       `json:"virtualServers"` // Full paths
+	Events         []string       `json:"events"`
+	Findings       []IRuleFinding `json:"findings"`
+	TCLErrors      int            `json:"tclErrors"` // "TCL error" lines naming the rule
+	LastTCLError   string         `json:"lastTclError,omitempty"`
+
+	lastTCLError time.Time
+}
+
+type ConfigHygiene struct {
+	UnusedPools       []string           `json:"unusedPools"`
+	UnusedMonitors    []string           `json:"unusedMonitors"`
+	UnusedProfiles    []string           `json:"unusedProfiles"`
+	UnusedRules       []string           `json:"unusedRules"`
+	UnusedDataGroups  []string           `json:"unusedDataGroups"`
+	OrphanNodes       []string           `json:"orphanNodes"` // Nodes in no pool
+	MissingReferences []MissingReference `json:"missingReferences"`
+}
+
+type MissingReference struct {
+	Kind           string `json:"kind"` // pool, monitor, profile, rule, data-group, node
+	Name           string `json:"name"`
+	ReferencedBy   string `json:"referencedBy"`
+	ReferencedKind string `json:"referencedKind"`
+	Relation       string `json:"relation"`
+}
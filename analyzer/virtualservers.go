@@ -2,7 +2,6 @@ package analyzer
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"goqkview/interfaces"
@@ -16,40 +15,67 @@ func NewVirtualServerAnalyzer() *VirtualServerAnalyzer {
 }
 
 type VirtualServerInfo struct {
-	Name          string  `json:"name"`
-	Pool          string  `json:"pool"`
-	Status        string  `json:"status"`        // healthy, warning, critical
-	ActiveMembers string  `json:"activeMembers"` // "X/Y" format
-	LastError     *string `json:"lastError"`     // null or "Error message - YYYY-MM-DD HH:MM:SS"
+	Name          string       `json:"name"`
+	Path          string       `json:"path"` // Full path, e.g. /Common/vs_web
+	Pool          string       `json:"pool"`
+	Destination   string       `json:"destination,omitempty"` // Parsed listener, e.g. 10.1.1.1%2:443
+	Status        string       `json:"status"`                // healthy, warning, critical
+	ActiveMembers string       `json:"activeMembers"`         // "X/Y" format
+	Availability  string       `json:"availability"`          // available, offline, unknown, disabled
+	Reason        string       `json:"reason,omitempty"`
+	Members       []MemberInfo `json:"members,omitempty"`
+	LastError     *string      `json:"lastError"` // null or "Error message - YYYY-MM-DD HH:MM:SS"
+
+	Findings []VirtualServerFinding `json:"findings,omitempty"`
 }
 
-func (v *VirtualServerAnalyzer) Analyze(config *parser.BigIPConfig, entries []interfaces.LogEntry) []VirtualServerInfo {
+type VirtualServerFinding struct {
+	Severity string `json:"severity"` // critical, warning, info
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+type MemberInfo struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+	FlapCount int    `json:"flapCount,omitempty"`
+	History   string `json:"history,omitempty"` // Monitor history summary from ltm logs
+}
+
+func (v *VirtualServerAnalyzer) Analyze(config *parser.BigIPConfig, entries []interfaces.LogEntry, histories []MemberHistory) []VirtualServerInfo {
 	if config == nil {
 		return []VirtualServerInfo{}
 	}
 
+	historyByMember := make(map[string]MemberHistory, len(histories))
+	for _, h := range histories {
+		historyByMember[memberKey(h.Pool, h.Member)] = h
+	}
+
 	results := []VirtualServerInfo{}
 
-	vsNames := make([]string, 0, len(config.VirtualServers))
-	for name := range config.VirtualServers {
-		vsNames = append(vsNames, name)
-	}
-	sort.Strings(vsNames)
+	lastErrors := v.lastErrors(config, entries)
 
-	for _, vsName := range vsNames {
-		vs := config.VirtualServers[vsName]
+	for _, path := range sortedKeys(config.ByPath.VirtualServers) {
+		vs := config.ByPath.VirtualServers[path]
 		info := VirtualServerInfo{
 			Name: vs.Name,
+			Path: path,
 			Pool: vs.Pool,
 		}
 
 		if vs.Disabled {
 			info.Status = "critical"
 			info.ActiveMembers = "0/0"
-		} else if pool, ok := config.Pools[vs.Pool]; ok {
+			info.Availability = "disabled"
+			info.Reason = "Virtual server is disabled"
+		} else if pool, ok := config.ByPath.Pools[vs.PoolPath]; ok {
 			active := pool.GetActiveMembers()
 			total := pool.GetTotalMembers()
 			info.ActiveMembers = fmt.Sprintf("%d/%d", active, total)
+			info.Availability, info.Reason = v.poolAvailability(pool, active, total)
+			info.Members = v.memberInfo(pool, historyByMember)
 
 			if total == 0 {
 				info.Status = "critical"
@@ -63,41 +89,110 @@ func (v *VirtualServerAnalyzer) Analyze(config *parser.BigIPConfig, entries []in
 		} else {
 			info.Status = "warning"
 			info.ActiveMembers = "0/0"
+			info.Availability = "unknown"
+			if vs.Pool == "" {
+				info.Reason = "No default pool"
+			} else {
+				info.Reason = fmt.Sprintf("Pool %s not found in configuration", vs.PoolPath)
+			}
 		}
 
-		info.LastError = v.findLastError(vs.Name, vs.Pool, entries)
+		if entry, ok := lastErrors[path]; ok {
+			info.LastError = v.formatError(entry)
+		}
 
 		results = append(results, info)
 	}
 
+	v.checkListeners(config, results)
+
 	return results
 }
 
-func (v *VirtualServerAnalyzer) findLastError(vsName, poolName string, entries []interfaces.LogEntry) *string {
-	sortedEntries := make([]interfaces.LogEntry, len(entries))
-	copy(sortedEntries, entries)
-	sort.Slice(sortedEntries, func(i, j int) bool {
-		return sortedEntries[i].Timestamp.After(sortedEntries[j].Timestamp)
-	})
+func (v *VirtualServerAnalyzer) memberInfo(pool *parser.PoolConfig, histories map[string]MemberHistory) []MemberInfo {
+	members := make([]MemberInfo, 0, len(pool.Members))
+	for _, m := range pool.Members {
+		info := MemberInfo{
+			Name:      m.Name,
+			Available: m.Available(),
+			Reason:    m.UnavailableReason(),
+		}
+		if h, ok := histories[memberKey(pool.Path, m.Name)]; ok {
+			info.FlapCount = h.FlapCount
+			info.History = h.Summary
+		}
+		members = append(members, info)
+	}
+	return members
+}
 
-	for _, entry := range sortedEntries {
-		if entry.Status != "ERROR" && entry.Status != "CRITICAL" && entry.Status != "SEVERE" {
-			continue
+func (v *VirtualServerAnalyzer) poolAvailability(pool *parser.PoolConfig, active, total int) (string, string) {
+	if total == 0 {
+		return "offline", "Pool has no members"
+	}
+
+	unavailable := []string{}
+	for _, m := range pool.Members {
+		if !m.Available() {
+			unavailable = append(unavailable, fmt.Sprintf("%s (%s)", m.Name, m.UnavailableReason()))
 		}
+	}
 
-		lineLower := strings.ToLower(entry.Line)
-		vsNameLower := strings.ToLower(vsName)
-		poolNameLower := strings.ToLower(poolName)
+	switch {
+	case active == 0:
+		return "offline", "All members unavailable: " + strings.Join(unavailable, ", ")
+	case !pool.HasRuntimeStatus() && len(unavailable) == 0:
+		return "unknown", "No runtime monitor status; based on configuration only"
+	case len(unavailable) > 0:
+		return "available", fmt.Sprintf("%d of %d members unavailable: %s",
+			len(unavailable), total, strings.Join(unavailable, ", "))
+	}
+	return "available", ""
+}
 
-		if strings.Contains(lineLower, vsNameLower) || strings.Contains(lineLower, poolNameLower) {
-			errorMsg := v.extractErrorMessage(entry.Line)
-			timestamp := entry.Timestamp.Format("2006-01-02 15:04:05")
-			result := fmt.Sprintf("%s - %s", errorMsg, timestamp)
-			return &result
+// lastErrors finds the latest error line naming each virtual server or its
+// pool, in one pass over the entries' object references. It is keyed by the
+// virtual server's full path.
+func (v *VirtualServerAnalyzer) lastErrors(config *parser.BigIPConfig, entries []interfaces.LogEntry) map[string]interfaces.LogEntry {
+	vsByPool := make(map[string][]string)
+	for path, vs := range config.ByPath.VirtualServers {
+		if vs.PoolPath != "" {
+			vsByPool[vs.PoolPath] = append(vsByPool[vs.PoolPath], path)
 		}
 	}
 
-	return nil
+	last := make(map[string]interfaces.LogEntry)
+	see := func(vsPath string, entry interfaces.LogEntry) {
+		if prev, ok := last[vsPath]; !ok || entry.Timestamp.After(prev.Timestamp) {
+			last[vsPath] = entry
+		}
+	}
+	for _, entry := range entries {
+		if !isErrorStatus(entry.Status) {
+			continue
+		}
+		for _, ref := range entry.Objects {
+			switch ref.Kind {
+			case parser.ObjectVirtual:
+				see(ref.Path, entry)
+			case parser.ObjectPool:
+				for _, vsPath := range vsByPool[ref.Path] {
+					see(vsPath, entry)
+				}
+			}
+		}
+	}
+	return last
+}
+
+func (v *VirtualServerAnalyzer) formatError(entry interfaces.LogEntry) *string {
+	errorMsg := v.extractErrorMessage(entry.Line)
+	if entry.Message != "" {
+		errorMsg = truncate(entry.Message, 100)
+	}
+	timestamp := entry.Timestamp.Format("2006-01-02 15:04:05")
+	result := fmt.Sprintf("%s - %s", errorMsg, timestamp)
+	return &result
 }
 
 func (v *VirtualServerAnalyzer) extractErrorMessage(line string) string {
@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed messages.yaml
+var builtin []byte
+
+// Message describes what a BIG-IP message ID means and what to do about it.
+type Message struct {
+	ID        string `yaml:"id"`
+	Component string `yaml:"component"`
+	Meaning   string `yaml:"meaning"`
+	Severity  string `yaml:"severity"` // critical, error, warning, info
+	Action    string `yaml:"action"`
+}
+
+type Catalog struct {
+	messages map[string]Message
+}
+
+type file struct {
+	Messages []Message `yaml:"messages"`
+}
+
+// Default returns the catalog shipped with goqkview.
+func Default() *Catalog {
+	c := &Catalog{messages: make(map[string]Message)}
+	if err := c.merge(builtin); err != nil {
+		panic(fmt.Sprintf("catalog: embedded messages.yaml: %v", err))
+	}
+	return c
+}
+
+// Load returns the built-in catalog extended by the YAML file at path. An
+// entry whose id is already known replaces only the fields it sets.
+func Load(path string) (*Catalog, error) {
+	c := Default()
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: failed to read %s: %w", path, err)
+	}
+	if err := c.merge(data); err != nil {
+		return nil, fmt.Errorf("catalog: %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Catalog) merge(data []byte) error {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	for i, m := range f.Messages {
+		id := normalizeID(m.ID)
+		if id == "" {
+			return fmt.Errorf("message %d has no id", i+1)
+		}
+		switch m.Severity {
+		case "", "critical", "error", "warning", "info":
+		default:
+			return fmt.Errorf("message %s has invalid severity %q", m.ID, m.Severity)
+		}
+
+		merged := c.messages[id]
+		merged.ID = id
+		setIfSet(&merged.Component, m.Component)
+		setIfSet(&merged.Meaning, m.Meaning)
+		setIfSet(&merged.Severity, m.Severity)
+		setIfSet(&merged.Action, m.Action)
+		c.messages[id] = merged
+	}
+	return nil
+}
+
+// Lookup finds a message by ID. Both "01070638" and "01070638:5:" work.
+func (c *Catalog) Lookup(id string) (Message, bool) {
+	m, ok := c.messages[normalizeID(id)]
+	return m, ok
+}
+
+func (c *Catalog) Len() int {
+	return len(c.messages)
+}
+
+func normalizeID(id string) string {
+	id = strings.TrimSpace(id)
+	if i := strings.Index(id, ":"); i != -1 {
+		id = id[:i]
+	}
+	return strings.ToUpper(id)
+}
+
+func setIfSet(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
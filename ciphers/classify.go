@@ -0,0 +1,53 @@
+package ciphers
+
+import "strings"
+
+type Strength string
+
+const (
+	Insecure Strength = "insecure"
+	Weak     Strength = "weak"
+	Strong   Strength = "strong"
+)
+
+// Classify grades a suite and returns the reason for anything below strong.
+func Classify(s Suite) (Strength, string) {
+	switch {
+	case s.Enc == "NULL":
+		return Insecure, "no encryption"
+	case s.Au == "NULL":
+		return Insecure, "anonymous key exchange"
+	case s.Export:
+		return Insecure, "export-grade key length"
+	case s.Enc == "RC4":
+		return Insecure, "RC4 stream cipher"
+	case s.Enc == "DES":
+		return Insecure, "single DES"
+	case s.Mac == "MD5":
+		return Insecure, "MD5 MAC"
+	case s.Enc == "3DES":
+		return Weak, "3DES (Sweet32)"
+	case s.Kx == "RSA":
+		return Weak, "static RSA key exchange without forward secrecy"
+	case s.Mac != "AEAD":
+		return Weak, "CBC mode cipher"
+	}
+	return Strong, ""
+}
+
+// FindInText returns the known suites named in free text, such as a log line.
+func (t *Table) FindInText(text string) []Suite {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	})
+
+	var found []Suite
+	seen := make(map[string]bool)
+	for _, w := range words {
+		if s, ok := t.Lookup(w); ok && !seen[s.Name] {
+			found = append(found, s)
+			seen[s.Name] = true
+		}
+	}
+	return found
+}
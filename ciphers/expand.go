@@ -0,0 +1,220 @@
+package ciphers
+
+import (
+	"sort"
+	"strings"
+)
+
+type Expansion struct {
+	Suites  []Suite
+	Unknown []string // Tokens that matched no alias or suite name
+}
+
+// Expand resolves a BIG-IP/OpenSSL cipher string such as
+// "DEFAULT:!RC4:ECDHE+AES-GCM" into the ordered list of enabled suites.
+// Rules follow OpenSSL semantics: "!" removes permanently, "-" removes,
+// "+" moves matching suites to the end, and "A+B" selects the intersection.
+func (t *Table) Expand(spec string) Expansion {
+	e := &expander{table: t, killed: make(map[string]bool)}
+	e.apply(spec, 0)
+	return Expansion{Suites: e.active, Unknown: e.unknown}
+}
+
+type expander struct {
+	table   *Table
+	active  []Suite
+	killed  map[string]bool
+	unknown []string
+}
+
+func (e *expander) apply(spec string, depth int) {
+	tokens := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ':' || r == ',' || r == ' '
+	})
+
+	for _, token := range tokens {
+		op := byte(0)
+		if token[0] == '!' || token[0] == '-' || token[0] == '+' {
+			op = token[0]
+			token = token[1:]
+		}
+		if token == "" {
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(token, "@STRENGTH"):
+			sort.SliceStable(e.active, func(i, j int) bool {
+				return e.active[i].Bits > e.active[j].Bits
+			})
+			continue
+		case strings.HasPrefix(strings.ToUpper(token), "@SECLEVEL"):
+			continue
+		case strings.EqualFold(token, "DEFAULT") && op == 0 && depth == 0:
+			e.apply(e.table.Default, depth+1)
+			continue
+		case strings.EqualFold(token, "NONE"):
+			continue
+		}
+
+		selected, ok := e.selectSuites(token, depth)
+		if !ok {
+			e.unknown = append(e.unknown, token)
+			continue
+		}
+
+		switch op {
+		case '!':
+			for _, s := range selected {
+				e.killed[s.Name] = true
+			}
+			e.remove(selected)
+		case '-':
+			e.remove(selected)
+		case '+':
+			e.moveToEnd(selected)
+		default:
+			e.add(selected)
+		}
+	}
+}
+
+func (e *expander) selectSuites(token string, depth int) ([]Suite, bool) {
+	if s, ok := e.table.Lookup(token); ok {
+		return []Suite{s}, true
+	}
+
+	if strings.EqualFold(token, "DEFAULT") {
+		if depth > 0 {
+			return nil, false
+		}
+		return e.table.Expand(e.table.Default).Suites, true
+	}
+
+	parts := strings.Split(token, "+")
+	for _, part := range parts {
+		if _, known := aliases[strings.ToUpper(part)]; !known {
+			return nil, false
+		}
+	}
+
+	var selected []Suite
+	for _, s := range e.table.Suites {
+		match := true
+		for _, part := range parts {
+			if !aliases[strings.ToUpper(part)](s) {
+				match = false
+				break
+			}
+		}
+		if match {
+			selected = append(selected, s)
+		}
+	}
+	return selected, true
+}
+
+func (e *expander) add(selected []Suite) {
+	for _, s := range selected {
+		if e.killed[s.Name] || e.indexOf(s.Name) != -1 {
+			continue
+		}
+		e.active = append(e.active, s)
+	}
+}
+
+func (e *expander) remove(selected []Suite) {
+	drop := make(map[string]bool, len(selected))
+	for _, s := range selected {
+		drop[s.Name] = true
+	}
+	kept := e.active[:0]
+	for _, s := range e.active {
+		if !drop[s.Name] {
+			kept = append(kept, s)
+		}
+	}
+	e.active = kept
+}
+
+func (e *expander) moveToEnd(selected []Suite) {
+	move := make(map[string]bool, len(selected))
+	for _, s := range selected {
+		move[s.Name] = true
+	}
+	var kept, moved []Suite
+	for _, s := range e.active {
+		if move[s.Name] {
+			moved = append(moved, s)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	e.active = append(kept, moved...)
+}
+
+func (e *expander) indexOf(name string) int {
+	for i, s := range e.active {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// There is no TLSV1_1 alias: suites record their minimum protocol and none is
+// new in TLS 1.1, so the table cannot tell TLSv1.1 suites from TLSv1 ones.
+var aliases = map[string]func(Suite) bool{
+	"ALL":      func(s Suite) bool { return s.Enc != "NULL" },
+	"NATIVE":   func(s Suite) bool { return !s.Compat },
+	"COMPAT":   func(s Suite) bool { return s.Compat },
+	"HIGH":     func(s Suite) bool { return s.Bits >= 128 && s.Enc != "RC4" && s.Enc != "3DES" },
+	"MEDIUM":   func(s Suite) bool { return s.Enc == "RC4" || s.Enc == "3DES" },
+	"LOW":      func(s Suite) bool { return s.Enc == "DES" && !s.Export },
+	"EXPORT":   func(s Suite) bool { return s.Export },
+	"EXP":      func(s Suite) bool { return s.Export },
+	"RSA":      func(s Suite) bool { return s.Kx == "RSA" },
+	"KRSA":     func(s Suite) bool { return s.Kx == "RSA" },
+	"ARSA":     func(s Suite) bool { return s.Au == "RSA" },
+	"ECDSA":    func(s Suite) bool { return s.Au == "ECDSA" },
+	"AECDSA":   func(s Suite) bool { return s.Au == "ECDSA" },
+	"ECDHE":    func(s Suite) bool { return s.Kx == "ECDHE" },
+	"ECDH":     func(s Suite) bool { return s.Kx == "ECDHE" },
+	"EECDH":    func(s Suite) bool { return s.Kx == "ECDHE" },
+	"KEECDH":   func(s Suite) bool { return s.Kx == "ECDHE" },
+	"KECDHE":   func(s Suite) bool { return s.Kx == "ECDHE" },
+	"DHE":      func(s Suite) bool { return s.Kx == "DHE" && s.Au != "NULL" },
+	"EDH":      func(s Suite) bool { return s.Kx == "DHE" && s.Au != "NULL" },
+	"KEDH":     func(s Suite) bool { return s.Kx == "DHE" },
+	"KDHE":     func(s Suite) bool { return s.Kx == "DHE" },
+	"ADH":      func(s Suite) bool { return s.Kx == "DHE" && s.Au == "NULL" },
+	"ANULL":    func(s Suite) bool { return s.Au == "NULL" },
+	"ENULL":    func(s Suite) bool { return s.Enc == "NULL" },
+	"NULL":     func(s Suite) bool { return s.Enc == "NULL" },
+	"AES":      func(s Suite) bool { return s.Enc == "AES" || s.Enc == "AESGCM" },
+	"AES128":   func(s Suite) bool { return (s.Enc == "AES" || s.Enc == "AESGCM") && s.Bits == 128 },
+	"AES256":   func(s Suite) bool { return (s.Enc == "AES" || s.Enc == "AESGCM") && s.Bits == 256 },
+	"AESGCM":   func(s Suite) bool { return s.Enc == "AESGCM" },
+	"AES-GCM":  func(s Suite) bool { return s.Enc == "AESGCM" },
+	"CHACHA20": func(s Suite) bool { return s.Enc == "CHACHA20" },
+	"CAMELLIA": func(s Suite) bool { return s.Enc == "CAMELLIA" },
+	"3DES":     func(s Suite) bool { return s.Enc == "3DES" },
+	"DES":      func(s Suite) bool { return s.Enc == "DES" },
+	"RC4":      func(s Suite) bool { return s.Enc == "RC4" },
+	"MD5":      func(s Suite) bool { return s.Mac == "MD5" },
+	"SHA":      func(s Suite) bool { return s.Mac == "SHA1" },
+	"SHA1":     func(s Suite) bool { return s.Mac == "SHA1" },
+	"SHA256":   func(s Suite) bool { return s.Mac == "SHA256" || strings.HasSuffix(s.Name, "SHA256") },
+	"SHA384":   func(s Suite) bool { return s.Mac == "SHA384" || strings.HasSuffix(s.Name, "SHA384") },
+	"SSLV3":    func(s Suite) bool { return s.Protocol == "SSLv3" },
+	"TLSV1":    func(s Suite) bool { return s.Protocol == "SSLv3" },
+	"TLSV1_2":  func(s Suite) bool { return s.Protocol == "TLSv1.2" },
+	"TLSV1.2":  func(s Suite) bool { return s.Protocol == "TLSv1.2" },
+	"TLSV1_3":  func(s Suite) bool { return s.Protocol == "TLSv1.3" },
+	"TLSV1.3":  func(s Suite) bool { return s.Protocol == "TLSv1.3" },
+	"FIPS": func(s Suite) bool {
+		return s.Bits >= 112 && s.Enc != "RC4" && s.Enc != "CHACHA20" && s.Enc != "CAMELLIA" && s.Au != "NULL"
+	},
+	"AEAD":            func(s Suite) bool { return s.Mac == "AEAD" },
+	"COMPLEMENTOFALL": func(s Suite) bool { return s.Enc == "NULL" },
+}
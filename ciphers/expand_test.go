@@ -0,0 +1,56 @@
+package ciphers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testTable is a small table in strongest-first order, like the real one.
+var testTable = &Table{
+	Default: "ALL:!RC4:@STRENGTH",
+	Suites: []Suite{
+		{Name: "ECDHE-RSA-AES256-GCM-SHA384", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 256},
+		{Name: "ECDHE-RSA-AES128-CBC-SHA", Protocol: "SSLv3", Kx: "ECDHE", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 128},
+		{Name: "AES256-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 256},
+		{Name: "DES-CBC3-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "3DES", Mac: "SHA1", Bits: 112},
+		{Name: "RC4-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "RC4", Mac: "SHA1", Bits: 128},
+		{Name: "RC4-MD5", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "RC4", Mac: "MD5", Bits: 128},
+	},
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		unknown []string
+	}{
+		{"RC4-SHA:AES256-SHA", []string{"RC4-SHA", "AES256-SHA"}, nil},
+		// ! removes for good, - only until the suite is added again.
+		{"RC4:!RC4-SHA:RC4-SHA", []string{"RC4-MD5"}, nil},
+		{"RC4-SHA:AES256-SHA:-RC4-SHA:RC4-SHA", []string{"AES256-SHA", "RC4-SHA"}, nil},
+		// + moves matching suites to the end.
+		{"RC4-SHA:AES256-SHA:+RC4", []string{"AES256-SHA", "RC4-SHA"}, nil},
+		{"DES-CBC3-SHA:RC4-MD5:AES256-SHA:@STRENGTH", []string{"AES256-SHA", "RC4-MD5", "DES-CBC3-SHA"}, nil},
+		// A+B is the intersection of both aliases.
+		{"ECDHE+AESGCM", []string{"ECDHE-RSA-AES256-GCM-SHA384"}, nil},
+		{"kRSA+SHA1:!3DES", []string{"AES256-SHA", "RC4-SHA"}, nil},
+		{"TLSv1_2", []string{"ECDHE-RSA-AES256-GCM-SHA384"}, nil},
+		{"MEDIUM", []string{"DES-CBC3-SHA", "RC4-SHA", "RC4-MD5"}, nil},
+		{"DEFAULT:-3DES", []string{"ECDHE-RSA-AES256-GCM-SHA384", "AES256-SHA", "ECDHE-RSA-AES128-CBC-SHA"}, nil},
+		// TLSv1_1 has no alias: the table cannot tell it from TLSv1.
+		{"TLSv1_1:AES256-SHA:BOGUS", []string{"AES256-SHA"}, []string{"TLSv1_1", "BOGUS"}},
+	}
+	for _, tt := range tests {
+		got := testTable.Expand(tt.spec)
+		var names []string
+		for _, s := range got.Suites {
+			names = append(names, s.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("Expand(%q) = %v, want %v", tt.spec, names, tt.want)
+		}
+		if !reflect.DeepEqual(got.Unknown, tt.unknown) {
+			t.Errorf("Expand(%q) unknown = %v, want %v", tt.spec, got.Unknown, tt.unknown)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package ciphers
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Suite struct {
+	Name     string // OpenSSL-style name as used in BIG-IP cipher strings
+	Protocol string // Minimum protocol: SSLv3, TLSv1.2, TLSv1.3
+	Kx       string // Key exchange: RSA, DHE, ECDHE, ANY (TLS 1.3)
+	Au       string // Authentication: RSA, ECDSA, NULL, ANY (TLS 1.3)
+	Enc      string // Bulk cipher: AESGCM, AES, CHACHA20, CAMELLIA, 3DES, DES, RC4, NULL
+	Mac      string // AEAD, SHA384, SHA256, SHA1, MD5
+	Bits     int    // Effective symmetric key strength
+	Export   bool
+	Compat   bool   // Only available on the COMPAT (OpenSSL) stack
+	Since    string // First BIG-IP version shipping the suite
+}
+
+type Table struct {
+	Version string
+	Default string // Expansion of the DEFAULT keyword for this version
+	Suites  []Suite
+}
+
+// Ordered strongest first; alias selection preserves this order, like the
+// native cipher list shown by "tmm --clientciphers".
+var suites = []Suite{
+	{Name: "TLS_AES_256_GCM_SHA384", Protocol: "TLSv1.3", Kx: "ANY", Au: "ANY", Enc: "AESGCM", Mac: "AEAD", Bits: 256, Since: "14.1"},
+	{Name: "TLS_CHACHA20_POLY1305_SHA256", Protocol: "TLSv1.3", Kx: "ANY", Au: "ANY", Enc: "CHACHA20", Mac: "AEAD", Bits: 256, Since: "14.1"},
+	{Name: "TLS_AES_128_GCM_SHA256", Protocol: "TLSv1.3", Kx: "ANY", Au: "ANY", Enc: "AESGCM", Mac: "AEAD", Bits: 128, Since: "14.1"},
+	{Name: "ECDHE-ECDSA-AES256-GCM-SHA384", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "ECDSA", Enc: "AESGCM", Mac: "AEAD", Bits: 256, Since: "12.0"},
+	{Name: "ECDHE-RSA-AES256-GCM-SHA384", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 256, Since: "11.5"},
+	{Name: "ECDHE-ECDSA-CHACHA20-POLY1305", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "ECDSA", Enc: "CHACHA20", Mac: "AEAD", Bits: 256, Since: "13.0"},
+	{Name: "ECDHE-RSA-CHACHA20-POLY1305", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "RSA", Enc: "CHACHA20", Mac: "AEAD", Bits: 256, Since: "13.0"},
+	{Name: "ECDHE-ECDSA-AES128-GCM-SHA256", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "ECDSA", Enc: "AESGCM", Mac: "AEAD", Bits: 128, Since: "12.0"},
+	{Name: "ECDHE-RSA-AES128-GCM-SHA256", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 128, Since: "11.5"},
+	{Name: "DHE-RSA-AES256-GCM-SHA384", Protocol: "TLSv1.2", Kx: "DHE", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 256, Since: "11.5"},
+	{Name: "DHE-RSA-AES128-GCM-SHA256", Protocol: "TLSv1.2", Kx: "DHE", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 128, Since: "11.5"},
+	{Name: "ECDHE-ECDSA-AES256-SHA384", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "ECDSA", Enc: "AES", Mac: "SHA384", Bits: 256, Since: "12.0"},
+	{Name: "ECDHE-RSA-AES256-SHA384", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "RSA", Enc: "AES", Mac: "SHA384", Bits: 256, Since: "11.5"},
+	{Name: "ECDHE-ECDSA-AES128-SHA256", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "ECDSA", Enc: "AES", Mac: "SHA256", Bits: 128, Since: "12.0"},
+	{Name: "ECDHE-RSA-AES128-SHA256", Protocol: "TLSv1.2", Kx: "ECDHE", Au: "RSA", Enc: "AES", Mac: "SHA256", Bits: 128, Since: "11.5"},
+	{Name: "DHE-RSA-AES256-SHA256", Protocol: "TLSv1.2", Kx: "DHE", Au: "RSA", Enc: "AES", Mac: "SHA256", Bits: 256, Since: "11.5"},
+	{Name: "DHE-RSA-AES128-SHA256", Protocol: "TLSv1.2", Kx: "DHE", Au: "RSA", Enc: "AES", Mac: "SHA256", Bits: 128, Since: "11.5"},
+	{Name: "ECDHE-ECDSA-AES256-SHA", Protocol: "SSLv3", Kx: "ECDHE", Au: "ECDSA", Enc: "AES", Mac: "SHA1", Bits: 256, Since: "12.0"},
+	{Name: "ECDHE-RSA-AES256-CBC-SHA", Protocol: "SSLv3", Kx: "ECDHE", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 256, Since: "11.5"},
+	{Name: "ECDHE-ECDSA-AES128-SHA", Protocol: "SSLv3", Kx: "ECDHE", Au: "ECDSA", Enc: "AES", Mac: "SHA1", Bits: 128, Since: "12.0"},
+	{Name: "ECDHE-RSA-AES128-CBC-SHA", Protocol: "SSLv3", Kx: "ECDHE", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 128, Since: "11.5"},
+	{Name: "DHE-RSA-AES256-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 256, Since: "11.5"},
+	{Name: "DHE-RSA-AES128-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 128, Since: "11.5"},
+	{Name: "DHE-RSA-CAMELLIA256-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "RSA", Enc: "CAMELLIA", Mac: "SHA1", Bits: 256, Since: "11.5"},
+	{Name: "DHE-RSA-CAMELLIA128-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "RSA", Enc: "CAMELLIA", Mac: "SHA1", Bits: 128, Since: "11.5"},
+	{Name: "AES256-GCM-SHA384", Protocol: "TLSv1.2", Kx: "RSA", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 256, Since: "11.5"},
+	{Name: "AES128-GCM-SHA256", Protocol: "TLSv1.2", Kx: "RSA", Au: "RSA", Enc: "AESGCM", Mac: "AEAD", Bits: 128, Since: "11.5"},
+	{Name: "AES256-SHA256", Protocol: "TLSv1.2", Kx: "RSA", Au: "RSA", Enc: "AES", Mac: "SHA256", Bits: 256, Since: "11.5"},
+	{Name: "AES128-SHA256", Protocol: "TLSv1.2", Kx: "RSA", Au: "RSA", Enc: "AES", Mac: "SHA256", Bits: 128, Since: "11.5"},
+	{Name: "AES256-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 256, Since: "11.5"},
+	{Name: "AES128-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "AES", Mac: "SHA1", Bits: 128, Since: "11.5"},
+	{Name: "CAMELLIA256-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "CAMELLIA", Mac: "SHA1", Bits: 256, Since: "11.5"},
+	{Name: "CAMELLIA128-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "CAMELLIA", Mac: "SHA1", Bits: 128, Since: "11.5"},
+	{Name: "ECDHE-RSA-DES-CBC3-SHA", Protocol: "SSLv3", Kx: "ECDHE", Au: "RSA", Enc: "3DES", Mac: "SHA1", Bits: 112, Since: "11.5"},
+	{Name: "EDH-RSA-DES-CBC3-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "RSA", Enc: "3DES", Mac: "SHA1", Bits: 112, Since: "11.5"},
+	{Name: "DES-CBC3-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "3DES", Mac: "SHA1", Bits: 112, Since: "11.5"},
+	{Name: "RC4-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "RC4", Mac: "SHA1", Bits: 128, Since: "11.5"},
+	{Name: "RC4-MD5", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "RC4", Mac: "MD5", Bits: 128, Since: "11.5"},
+	{Name: "ADH-AES256-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "NULL", Enc: "AES", Mac: "SHA1", Bits: 256, Compat: true, Since: "11.5"},
+	{Name: "ADH-AES128-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "NULL", Enc: "AES", Mac: "SHA1", Bits: 128, Compat: true, Since: "11.5"},
+	{Name: "EDH-RSA-DES-CBC-SHA", Protocol: "SSLv3", Kx: "DHE", Au: "RSA", Enc: "DES", Mac: "SHA1", Bits: 56, Since: "11.5"},
+	{Name: "DES-CBC-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "DES", Mac: "SHA1", Bits: 56, Since: "11.5"},
+	{Name: "EXP1024-DES-CBC-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "DES", Mac: "SHA1", Bits: 56, Export: true, Compat: true, Since: "11.5"},
+	{Name: "EXP-DES-CBC-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "DES", Mac: "SHA1", Bits: 40, Export: true, Compat: true, Since: "11.5"},
+	{Name: "EXP-RC4-MD5", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "RC4", Mac: "MD5", Bits: 40, Export: true, Compat: true, Since: "11.5"},
+	{Name: "NULL-SHA256", Protocol: "TLSv1.2", Kx: "RSA", Au: "RSA", Enc: "NULL", Mac: "SHA256", Bits: 0, Since: "11.5"},
+	{Name: "NULL-SHA", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "NULL", Mac: "SHA1", Bits: 0, Since: "11.5"},
+	{Name: "NULL-MD5", Protocol: "SSLv3", Kx: "RSA", Au: "RSA", Enc: "NULL", Mac: "MD5", Bits: 0, Compat: true, Since: "11.5"},
+}
+
+// The DEFAULT keyword has been tightened across releases: RC4 was dropped in
+// 12.0 and 3DES in 14.0, while TLS 1.3 suites are only enabled explicitly.
+var tables = []Table{
+	{Version: "11.5", Default: "ALL:-TLSv1_3:!COMPAT:!EXPORT:!aNULL:!DES:@STRENGTH"},
+	{Version: "12.0", Default: "ALL:-TLSv1_3:!COMPAT:!EXPORT:!aNULL:!DES:!RC4:@STRENGTH"},
+	{Version: "14.0", Default: "ALL:-TLSv1_3:!COMPAT:!EXPORT:!aNULL:!DES:!RC4:!3DES:!ADH:@STRENGTH"},
+}
+
+func Latest() *Table {
+	return TableFor("")
+}
+
+// TableFor returns the suite table for a BIG-IP version such as "15.1.8".
+// An empty or unparseable version selects the newest table.
+func TableFor(version string) *Table {
+	known := isVersion(version)
+
+	selected := tables[len(tables)-1]
+	if known {
+		selected = tables[0]
+		for _, t := range tables {
+			if compareVersions(version, t.Version) >= 0 {
+				selected = t
+			}
+		}
+	}
+
+	t := &Table{Version: selected.Version, Default: selected.Default}
+	for _, s := range suites {
+		if !known || compareVersions(version, s.Since) >= 0 {
+			t.Suites = append(t.Suites, s)
+		}
+	}
+	return t
+}
+
+func (t *Table) Lookup(name string) (Suite, bool) {
+	for _, s := range t.Suites {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return Suite{}, false
+}
+
+func isVersion(v string) bool {
+	_, err := strconv.Atoi(strings.SplitN(v, ".", 2)[0])
+	return err == nil
+}
+
+func compareVersions(a, b string) int {
+	ap := strings.Split(a, ".")
+	bp := strings.Split(b, ".")
+	for i := 0; i < len(ap) || i < len(bp); i++ {
+		var x, y int
+		if i < len(ap) {
+			x, _ = strconv.Atoi(ap[i])
+		}
+		if i < len(bp) {
+			y, _ = strconv.Atoi(bp[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
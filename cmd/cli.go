@@ -12,6 +12,7 @@ type Mode int
 const (
 	ModeDistributed Mode = iota
 	ModeLocal
+	ModeRulesLint
 )
 
 type Config struct {
@@ -19,14 +20,37 @@ type Config struct {
 	FilePath   string // For local mode: path to qkview file
 	OutputPath string // Output path for metadata.json
 	Stdout     bool   // Print to stdout instead of file
+
+	CatalogPath  string // YAML file extending the built-in message-ID catalog
+	ProfilesPath string // YAML file with log-file profiles that override the defaults
+	RulesPath    string // Directory of YAML rule files; the directory to lint in ModeRulesLint
+
+	TimelineResolution string // minute, hour, day or auto
+	TopErrors          int    // Error groups reported; 0 means all
+
+	GraphFormat    string // dot or json; writes the dependency graph instead of the analysis
+	GraphPartition string // Limit the graph to one partition
+	GraphRoot      string // Limit the graph to one object, "kind:name" or a bare name
 }
 
 func ParseFlags() (*Config, error) {
+	if len(os.Args) > 1 && os.Args[1] == "rules" {
+		return parseRulesCommand(os.Args[2:])
+	}
+
 	cfg := &Config{}
 
 	file := flag.String("file", "", "Path to qkview.tar.gz file (enables local mode)")
 	output := flag.String("output", "", "Output path for metadata.json (default: same directory as input)")
 	stdout := flag.Bool("stdout", false, "Print JSON output to stdout instead of file")
+	catalogPath := flag.String("catalog", "", "YAML file that extends or overrides the built-in message-ID catalog")
+	profilesPath := flag.String("profiles", "", "YAML file with log-file profiles that override the built-in ones")
+	rulesPath := flag.String("rules", "", "Directory of YAML rule files evaluated with the built-in checks")
+	topErrors := flag.Int("top-errors", 10, "Number of error groups to report, 0 for all")
+	timeline := flag.String("timeline", "auto", "Error timeline bucket size: minute, hour, day or auto")
+	graph := flag.String("graph", "", "Write the configuration dependency graph (dot or json) instead of the analysis")
+	graphPartition := flag.String("graph-partition", "", "Limit the graph to one partition")
+	graphRoot := flag.String("graph-root", "", "Limit the graph to an object, what it depends on and what depends on it, e.g. pool:web_pool")
 	help := flag.Bool("help", false, "Show help message")
 
 	flag.Parse()
@@ -36,6 +60,22 @@ func ParseFlags() (*Config, error) {
 		os.Exit(0)
 	}
 
+	switch *graph {
+	case "", "dot", "json":
+	default:
+		return nil, fmt.Errorf("invalid graph format %q (want dot or json)", *graph)
+	}
+
+	switch *timeline {
+	case "auto", "minute", "hour", "day":
+	default:
+		return nil, fmt.Errorf("invalid timeline resolution %q (want minute, hour, day or auto)", *timeline)
+	}
+
+	if *topErrors < 0 {
+		return nil, fmt.Errorf("invalid --top-errors %d (want 0 or more)", *topErrors)
+	}
+
 	if *file != "" {
 		cfg.Mode = ModeLocal
 
@@ -50,20 +90,43 @@ func ParseFlags() (*Config, error) {
 
 		cfg.FilePath = absPath
 		cfg.Stdout = *stdout
+		cfg.CatalogPath = *catalogPath
+		cfg.ProfilesPath = *profilesPath
+		cfg.RulesPath = *rulesPath
+		cfg.TimelineResolution = *timeline
+		cfg.TopErrors = *topErrors
+		cfg.GraphFormat = *graph
+		cfg.GraphPartition = *graphPartition
+		cfg.GraphRoot = *graphRoot
 
 		if *output != "" {
 			cfg.OutputPath = *output
 		} else if !*stdout {
 			dir := filepath.Dir(absPath)
-			cfg.OutputPath = filepath.Join(dir, "metadata.json")
+			name := "metadata.json"
+			if cfg.GraphFormat != "" {
+				name = "graph." + cfg.GraphFormat
+			}
+			cfg.OutputPath = filepath.Join(dir, name)
 		}
 	} else {
 		cfg.Mode = ModeDistributed
+		if *graph != "" {
+			return nil, fmt.Errorf("--graph requires --file")
+		}
 	}
 
 	return cfg, nil
 }
 
+// parseRulesCommand handles "goqkview rules lint DIR".
+func parseRulesCommand(args []string) (*Config, error) {
+	if len(args) != 2 || args[0] != "lint" {
+		return nil, fmt.Errorf("usage: goqkview rules lint DIR")
+	}
+	return &Config{Mode: ModeRulesLint, RulesPath: args[1]}, nil
+}
+
 func printUsage() {
 	fmt.Println(`GOQkview - Qkview Diagnostic File Processor
 
@@ -72,16 +135,28 @@ Usage:
   goqkview --file /path/to/qkview.tar.gz     Process a local file
   goqkview --file /path/to/file --stdout     Output JSON to stdout
   goqkview --file /path/to/file --output /custom/path/metadata.json
+  goqkview --file /path/to/file --graph dot --graph-root pool:web_pool
+  goqkview --file /path/to/file --rules ./rules
+  goqkview rules lint ./rules                Validate the rule files in a directory
 
 Options:
-  --file     Path to qkview.tar.gz file (enables local mode)
-  --output   Custom output path for metadata.json (default: same directory as input)
-  --stdout   Print JSON to stdout instead of writing to file
-  --help     Show this help message
+  --file             Path to qkview.tar.gz file (enables local mode)
+  --output           Custom output path for metadata.json (default: same directory as input)
+  --stdout           Print JSON to stdout instead of writing to file
+  --catalog          YAML file that extends or overrides the built-in message-ID catalog
+  --profiles         YAML file with log-file profiles that override the built-in ones
+  --rules            Directory of YAML rule files evaluated with the built-in checks
+  --timeline         Error timeline bucket size: minute, hour, day or auto (default)
+  --top-errors       Number of error groups to report (default 10, 0 for all)
+  --graph            Write the configuration dependency graph (dot or json) instead of the analysis
+  --graph-partition  Limit the graph to one partition
+  --graph-root       Limit the graph to an object, what it depends on and what depends on it
+  --help             Show this help message
 
 Environment Variables (distributed mode only):
   ENDPOINT, ACCESSKEY, SECRETKEY      MinIO configuration
   BOOTSTRAP, TOPIC, KAFKAUSER, etc.   Kafka configuration
   ELASTIC_ENDPOINT, ELASTIC_PASSWORD  Elasticsearch configuration
+  LOG_PROFILES (optional)             Log-file profiles YAML
   POSTGRES_HOST (optional)            PostgreSQL for tracking`)
 }
@@ -0,0 +1,39 @@
+package graph
+
+// Objects that ship with BIG-IP live in the base config files rather than
+// bigip.conf, so references to them are never reported as missing.
+var builtins = map[Kind]map[string]bool{
+	KindMonitor: set(
+		"none", "gateway_icmp", "icmp", "tcp", "tcp_half_open", "tcp_echo", "udp", "http", "https",
+		"http_head_f5", "https_443", "https_head_f5", "inband", "dns", "ftp", "smtp", "pop3", "imap",
+		"ldap", "mysql", "mssql", "oracle", "postgresql", "radius", "radius_accounting", "sip", "snmp_dca",
+		"real_server", "external", "firepass", "nntp", "scripted", "soap", "virtual_location", "wap", "wmi",
+	),
+	KindProfile: set(
+		"http", "http-explicit", "http-transparent", "tcp", "tcp-lan-optimized", "tcp-wan-optimized",
+		"tcp-mobile-optimized", "f5-tcp-lan", "f5-tcp-wan", "f5-tcp-mobile", "f5-tcp-progressive",
+		"udp", "udp_gtm_dns", "fastL4", "fasthttp", "clientssl", "clientssl-insecure-compatible",
+		"clientssl-secure", "serverssl", "serverssl-insecure-compatible", "oneconnect", "httpcompression",
+		"wan-optimized-compression", "webacceleration", "optimized-caching", "stream", "ftp", "dns",
+		"http2", "websocket", "sctp", "sip", "request-log", "analytics", "statistics", "xml",
+		"cookie", "source_addr", "dest_addr", "hash", "ssl", "universal", "sip_info", "rtsp",
+	),
+	KindRule: set(
+		"_sys_https_redirect", "_sys_auth_ldap", "_sys_auth_radius", "_sys_auth_ssl_cc_ldap",
+		"_sys_auth_ssl_crldp", "_sys_auth_ssl_ocsp", "_sys_auth_krbdelegate", "_sys_auth_tacacs",
+		"_sys_APM_ExchangeSupport_main", "_sys_APM_activesync",
+	),
+	KindDataGroup: set("aol", "images", "private_net"),
+}
+
+func IsBuiltin(kind Kind, name string) bool {
+	return builtins[kind][name]
+}
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var dotShapes = map[Kind]string{
+	KindVirtual:   "box",
+	KindPool:      "folder",
+	KindMember:    "ellipse",
+	KindNode:      "box3d",
+	KindMonitor:   "diamond",
+	KindProfile:   "component",
+	KindRule:      "note",
+	KindDataGroup: "cylinder",
+	KindCert:      "septagon",
+	KindKey:       "septagon",
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Missing objects are drawn
+// in red, built-in ones dashed.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph bigip {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\" fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\" fontsize=8];\n")
+
+	for _, node := range g.Nodes() {
+		attrs := fmt.Sprintf("label=%s shape=%s", dotQuote(string(node.Kind)+"\n"+node.Path), dotShapes[node.Kind])
+		if node.Missing {
+			attrs += " color=red fontcolor=red"
+		} else if node.Builtin {
+			attrs += " style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), attrs)
+	}
+
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Relation))
+	}
+
+	b.WriteString("}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("graph: failed to write DOT: %w", err)
+	}
+	return nil
+}
+
+type nodeLink struct {
+	Directed bool    `json:"directed"`
+	Nodes    []*Node `json:"nodes"`
+	Links    []Edge  `json:"links"`
+}
+
+// WriteJSON writes the graph in node-link JSON, the format read by d3 and
+// networkx.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	data := nodeLink{Directed: true, Nodes: g.Nodes(), Links: g.Edges()}
+	if data.Links == nil {
+		data.Links = []Edge{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("graph: failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,358 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+
+	"goqkview/parser"
+)
+
+type Kind string
+
+const (
+	KindVirtual   Kind = "virtual"
+	KindPool      Kind = "pool"
+	KindMember    Kind = "member"
+	KindNode      Kind = "node"
+	KindMonitor   Kind = "monitor"
+	KindProfile   Kind = "profile"
+	KindRule      Kind = "rule"
+	KindDataGroup Kind = "data-group"
+	KindCert      Kind = "certificate"
+	KindKey       Kind = "key"
+)
+
+type Node struct {
+	ID        string `json:"id"`
+	Kind      Kind   `json:"kind"`
+	Name      string `json:"name"`
+	Path      string `json:"path"` // Full path, e.g. /Common/web_pool
+	Partition string `json:"partition,omitempty"`
+	Builtin   bool   `json:"builtin,omitempty"` // Shipped with BIG-IP, not in bigip.conf
+	Missing   bool   `json:"missing,omitempty"` // Referenced but not defined
+}
+
+type Edge struct {
+	From     string `json:"source"`
+	To       string `json:"target"`
+	Relation string `json:"relation"` // pool, member, node, monitor, profile, rule, data-group, certificate, key, defaults-from
+}
+
+// Graph links configuration objects to the objects they reference.
+type Graph struct {
+	nodes map[string]*Node
+	out   map[string][]Edge
+	in    map[string][]Edge
+}
+
+// ID identifies an object by kind and full path, e.g. pool:/Common/web_pool.
+// Members are identified by the path of their pool and their name.
+func ID(kind Kind, path string) string {
+	return string(kind) + ":" + path
+}
+
+// Build creates the reference graph for a parsed configuration. Objects are
+// keyed by full path, so /Common/app and /Tenant/app are separate nodes.
+// Objects that are referenced but not defined are added with Missing or
+// Builtin set.
+func Build(config *parser.BigIPConfig) *Graph {
+	g := &Graph{
+		nodes: make(map[string]*Node),
+		out:   make(map[string][]Edge),
+		in:    make(map[string][]Edge),
+	}
+	if config == nil {
+		return g
+	}
+
+	objects := config.ByPath
+	for path, vs := range objects.VirtualServers {
+		g.define(KindVirtual, path, vs.Name, vs.Partition)
+	}
+	for path, pool := range objects.Pools {
+		g.define(KindPool, path, pool.Name, pool.Partition)
+	}
+	for path, node := range objects.Nodes {
+		g.define(KindNode, path, node.Name, node.Partition)
+	}
+	for path, monitor := range objects.Monitors {
+		g.define(KindMonitor, path, monitor.Name, monitor.Partition)
+	}
+	for path, profile := range objects.Profiles {
+		g.define(KindProfile, path, profile.Name, profile.Partition)
+	}
+	for path, rule := range objects.Rules {
+		g.define(KindRule, path, rule.Name, rule.Partition)
+	}
+	for path, dg := range objects.DataGroups {
+		g.define(KindDataGroup, path, dg.Name, dg.Partition)
+	}
+
+	for path, vs := range objects.VirtualServers {
+		from := ID(KindVirtual, path)
+		if vs.PoolPath != "" {
+			g.link(from, g.ref(KindPool, vs.PoolPath), "pool")
+		}
+		for _, profile := range vs.ProfilePaths {
+			g.link(from, g.ref(KindProfile, profile), "profile")
+		}
+		for _, rule := range vs.RulePaths {
+			g.link(from, g.ref(KindRule, rule), "rule")
+		}
+	}
+
+	for path, pool := range objects.Pools {
+		from := ID(KindPool, path)
+		for _, monitor := range pool.MonitorPaths {
+			g.link(from, g.ref(KindMonitor, monitor), "monitor")
+		}
+		for _, m := range pool.Members {
+			member := ID(KindMember, path+"/"+m.Name)
+			g.nodes[member] = &Node{ID: member, Kind: KindMember, Name: m.Name, Path: m.Path, Partition: pool.Partition}
+			g.link(from, member, "member")
+			g.link(member, g.ref(KindNode, m.NodePath()), "node")
+		}
+	}
+
+	for path, node := range objects.Nodes {
+		for _, monitor := range node.MonitorPaths {
+			g.link(ID(KindNode, path), g.ref(KindMonitor, monitor), "monitor")
+		}
+	}
+
+	for path, monitor := range objects.Monitors {
+		if monitor.DefaultsFromPath != "" && monitor.DefaultsFromPath != path {
+			g.link(ID(KindMonitor, path), g.ref(KindMonitor, monitor.DefaultsFromPath), "defaults-from")
+		}
+	}
+
+	for path, profile := range objects.Profiles {
+		if profile.DefaultsFromPath != "" && profile.DefaultsFromPath != path {
+			g.link(ID(KindProfile, path), g.ref(KindProfile, profile.DefaultsFromPath), "defaults-from")
+		}
+	}
+
+	// Certificates and keys live in the filestore rather than bigip.conf, so
+	// they are never reported as missing.
+	for path, profile := range objects.ClientSSLProfiles {
+		from := ID(KindProfile, path)
+		if profile.CertPath != "" {
+			g.link(from, g.file(KindCert, profile.CertPath), "certificate")
+		}
+		if profile.KeyPath != "" {
+			g.link(from, g.file(KindKey, profile.KeyPath), "key")
+		}
+	}
+
+	// iRules name pools and data groups without a path; they resolve in the
+	// rule's partition first.
+	for path, rule := range objects.Rules {
+		for _, ref := range rule.References() {
+			kind := Kind(ref.Kind)
+			target := rule.ReferencePath(ref, func(p string) bool { _, ok := g.nodes[ID(kind, p)]; return ok })
+			g.link(ID(KindRule, path), g.ref(kind, target), ref.Kind)
+		}
+	}
+
+	g.sortEdges()
+	return g
+}
+
+func (g *Graph) define(kind Kind, path, name, partition string) {
+	id := ID(kind, path)
+	g.nodes[id] = &Node{ID: id, Kind: kind, Name: name, Path: path, Partition: partition}
+}
+
+// ref returns the ID of the object at path, adding a placeholder node when
+// the configuration does not define it.
+func (g *Graph) ref(kind Kind, path string) string {
+	id := ID(kind, path)
+	if _, ok := g.nodes[id]; !ok {
+		// BIG-IP creates nodes implicitly for pool members, so an undeclared
+		// node is not a broken reference.
+		name, partition := parser.CleanName(path), parser.PartitionOf(path)
+		builtin := partition == "Common" && IsBuiltin(kind, name)
+		g.nodes[id] = &Node{ID: id, Kind: kind, Name: name, Path: path, Partition: partition,
+			Builtin: builtin, Missing: !builtin && kind != KindNode}
+	}
+	return id
+}
+
+func (g *Graph) file(kind Kind, path string) string {
+	id := ID(kind, path)
+	if _, ok := g.nodes[id]; !ok {
+		g.nodes[id] = &Node{ID: id, Kind: kind, Name: parser.CleanName(path), Path: path, Partition: parser.PartitionOf(path)}
+	}
+	return id
+}
+
+func (g *Graph) link(from, to, relation string) {
+	for _, e := range g.out[from] {
+		if e.To == to && e.Relation == relation {
+			return
+		}
+	}
+	edge := Edge{From: from, To: to, Relation: relation}
+	g.out[from] = append(g.out[from], edge)
+	g.in[to] = append(g.in[to], edge)
+}
+
+func (g *Graph) sortEdges() {
+	for _, edges := range g.out {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].To < edges[j].To })
+	}
+	for _, edges := range g.in {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].From < edges[j].From })
+	}
+}
+
+func (g *Graph) Node(id string) (*Node, bool) {
+	node, ok := g.nodes[id]
+	return node, ok
+}
+
+// Nodes returns every node sorted by ID.
+func (g *Graph) Nodes() []*Node {
+	nodes := make([]*Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+func (g *Graph) NodesOfKind(kind Kind) []*Node {
+	var nodes []*Node
+	for _, node := range g.Nodes() {
+		if node.Kind == kind {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Edges returns every edge sorted by source and target.
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for _, node := range g.Nodes() {
+		edges = append(edges, g.out[node.ID]...)
+	}
+	return edges
+}
+
+// References returns the edges leaving id, i.e. what the object depends on.
+func (g *Graph) References(id string) []Edge {
+	return g.out[id]
+}
+
+// ReferencedBy returns the edges pointing at id, i.e. what uses the object.
+func (g *Graph) ReferencedBy(id string) []Edge {
+	return g.in[id]
+}
+
+// Find resolves an object given as "kind:path", "kind:name", a full path or
+// a bare name. A name may match objects in several partitions, and without a
+// kind objects of several kinds.
+func (g *Graph) Find(ref string) []*Node {
+	if node, ok := g.nodes[ref]; ok {
+		return []*Node{node}
+	}
+	var kind Kind
+	if k, name, ok := strings.Cut(ref, ":"); ok {
+		if _, known := dotShapes[Kind(k)]; known {
+			kind, ref = Kind(k), name
+		}
+	}
+	var nodes []*Node
+	for _, node := range g.Nodes() {
+		if (kind == "" || node.Kind == kind) && (node.Name == ref || node.Path == ref) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Dependents returns every object that directly or transitively references
+// id, i.e. what is affected when id changes.
+func (g *Graph) Dependents(id string) []*Node {
+	return g.walk(id, func(e Edge) string { return e.From }, g.in)
+}
+
+// Dependencies returns every object id directly or transitively references.
+func (g *Graph) Dependencies(id string) []*Node {
+	return g.walk(id, func(e Edge) string { return e.To }, g.out)
+}
+
+func (g *Graph) walk(id string, next func(Edge) string, edges map[string][]Edge) []*Node {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	var nodes []*Node
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range edges[current] {
+			n := next(e)
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			nodes = append(nodes, g.nodes[n])
+			queue = append(queue, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// Around returns the subgraph of the given objects together with everything
+// they depend on and everything that depends on them.
+func (g *Graph) Around(ids ...string) *Graph {
+	keep := make(map[string]bool)
+	for _, id := range ids {
+		if _, ok := g.nodes[id]; !ok {
+			continue
+		}
+		keep[id] = true
+		for _, n := range g.Dependents(id) {
+			keep[n.ID] = true
+		}
+		for _, n := range g.Dependencies(id) {
+			keep[n.ID] = true
+		}
+	}
+	return g.subgraph(keep)
+}
+
+// InPartition returns the objects of one partition together with
+// everything they depend on, which usually includes objects in /Common.
+func (g *Graph) InPartition(partition string) *Graph {
+	keep := make(map[string]bool)
+	for id, node := range g.nodes {
+		if node.Partition != partition {
+			continue
+		}
+		keep[id] = true
+		for _, n := range g.Dependencies(id) {
+			keep[n.ID] = true
+		}
+	}
+	return g.subgraph(keep)
+}
+
+func (g *Graph) subgraph(keep map[string]bool) *Graph {
+	sub := &Graph{
+		nodes: make(map[string]*Node, len(keep)),
+		out:   make(map[string][]Edge),
+		in:    make(map[string][]Edge),
+	}
+	for id := range keep {
+		sub.nodes[id] = g.nodes[id]
+	}
+	for _, e := range g.Edges() {
+		if keep[e.From] && keep[e.To] {
+			sub.link(e.From, e.To, e.Relation)
+		}
+	}
+	sub.sortEdges()
+	return sub
+}
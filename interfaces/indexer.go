@@ -6,11 +6,54 @@ import (
 )
 
 type LogEntry struct {
-	Path      string    `json:"path"`
-	Line      string    `json:"line"`
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Source    string    `json:"source,omitempty"` // qkview filename
+	Path       string    `json:"path"`
+	LineNumber int       `json:"lineNumber,omitempty"` // 1-based line in Path; 0 for journal entries
+	Line       string    `json:"line"`
+	Body       string    `json:"body,omitempty"`      // Continuation lines (stack traces, wrapped messages), newline separated
+	Status     string    `json:"status"`              // EMERGENCY, ALERT, CRITICAL, ERROR, SEVERE, WARNING, NOTICE, INFO, DEBUG
+	Timestamp  time.Time `json:"timestamp"`           // UTC
+	UTCOffset  string    `json:"utcOffset,omitempty"` // Offset the line was written in, e.g. -08:00
+	Source     string    `json:"source,omitempty"`    // qkview filename
+	Hostname   string    `json:"hostname,omitempty"`  // Device the qkview was taken on
+	Facility   string    `json:"facility,omitempty"`  // Log the line came from: ltm, gtm, restjavad, ...
+
+	SeverityMethod string `json:"severityMethod,omitempty"` // How Status was found: syslog, message-id, header, keyword
+
+	// Syslog fields; empty for lines that are not in syslog format
+	Host      string `json:"host,omitempty"`     // Host named in the line
+	Severity  string `json:"severity,omitempty"` // Syslog keyword as written: err, warning, notice, ...
+	Process   string `json:"process,omitempty"`  // mcpd, tmm, ...
+	Unit      string `json:"unit,omitempty"`     // systemd unit, journal entries only
+	PID       int    `json:"pid,omitempty"`
+	MessageID string `json:"messageId,omitempty"` // BIG-IP message ID, e.g. 01070727
+	Message   string `json:"message,omitempty"`   // Text after the process and message ID
+
+	OutOfOrder bool `json:"outOfOrder,omitempty"` // Timestamp is well before the preceding line in the file
+
+	Audit *AuditRecord `json:"audit,omitempty"` // Set for records of the audit log
+
+	Objects []ObjectRef `json:"objects,omitempty"` // Configuration objects the message names
+}
+
+// ObjectRef is a configuration object named in a log line.
+type ObjectRef struct {
+	Kind string `json:"kind"`           // virtual, pool, member, node, rule, monitor, profile
+	Name string `json:"name"`           // Name in the parsed configuration, e.g. vs_web or 10.0.0.5:80
+	Path string `json:"path"`           // Full path, e.g. /Common/vs_web
+	Pool string `json:"pool,omitempty"` // Full path of the pool of a member
+}
+
+// AuditRecord is who did what through which interface, from one audit log line.
+type AuditRecord struct {
+	User      string `json:"user"`
+	Address   string `json:"address,omitempty"` // Client address, when logged
+	Interface string `json:"interface"`         // tmsh, iControl REST, iControl SOAP, GUI, ssh, ...
+	Action    string `json:"action"`            // create, modify, delete, load, ..., login
+	Object    string `json:"object,omitempty"`  // e.g. ltm pool /Common/app_pool
+	Command   string `json:"command,omitempty"` // Command or transaction as logged
+	Result    string `json:"result,omitempty"`  // Command OK or the error returned
+	Success   bool   `json:"success"`
+	Change    bool   `json:"change"` // Modifies the configuration
 }
 
 type LogIndexer interface {
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"goqkview/analyzer"
+	"goqkview/catalog"
 	"goqkview/cmd"
+	"goqkview/graph"
 	"goqkview/interfaces"
 	"goqkview/output"
 	"goqkview/parser"
@@ -18,6 +21,7 @@ import (
 	"goqkview/providers/local"
 	"goqkview/providers/minio"
 	"goqkview/repositories"
+	"goqkview/rules"
 )
 
 func main() {
@@ -44,6 +48,10 @@ func main() {
 			log.Printf("Local mode error: %v", err)
 			os.Exit(1)
 		}
+	case cmd.ModeRulesLint:
+		if !lintRules(cfg.RulesPath) {
+			os.Exit(1)
+		}
 	case cmd.ModeDistributed:
 		if err := runDistributedMode(ctx); err != nil && err != context.Canceled {
 			log.Printf("Distributed mode error: %v", err)
@@ -55,11 +63,28 @@ func main() {
 func runLocalMode(ctx context.Context, cfg *cmd.Config) error {
 	log.Printf("Processing local file: %s", cfg.FilePath)
 
+	messages, err := catalog.Load(cfg.CatalogPath)
+	if err != nil {
+		return err
+	}
+	profiles, err := parser.LoadProfiles(cfg.ProfilesPath)
+	if err != nil {
+		return err
+	}
+	ruleSet, err := rules.Load(cfg.RulesPath)
+	if err != nil {
+		return err
+	}
+	if ruleSet.Len() > 0 {
+		log.Printf("Loaded %d rules from %s", ruleSet.Len(), cfg.RulesPath)
+	}
+
 	storage := local.NewLocalStorage(cfg.FilePath)
 	events := local.NewLocalEventSource(cfg.FilePath)
 	indexer := local.NewMemoryIndexer()
 
 	p := parser.NewParser(parser.DateParseOptions{})
+	p.SetProfiles(profiles)
 
 	proc, err := processor.New(processor.Config{
 		Storage: storage,
@@ -81,8 +106,17 @@ func runLocalMode(ctx context.Context, cfg *cmd.Config) error {
 
 	bigipConfig := proc.GetBigIPConfig()
 
-	a := analyzer.New()
-	result, err := a.Analyze(entries, bigipConfig)
+	if cfg.GraphFormat != "" {
+		return writeGraph(cfg, bigipConfig)
+	}
+
+	a := analyzer.New(messages)
+	if err := a.SetTimelineResolution(cfg.TimelineResolution); err != nil {
+		return err
+	}
+	a.SetTopErrors(cfg.TopErrors)
+	a.SetRules(ruleSet)
+	result, err := a.Analyze(entries, bigipConfig, proc.GetDevice())
 	if err != nil {
 		return err
 	}
@@ -99,6 +133,51 @@ func runLocalMode(ctx context.Context, cfg *cmd.Config) error {
 	return nil
 }
 
+// lintRules prints every problem in the rule files of dir and reports
+// whether there were none.
+func lintRules(dir string) bool {
+	valid, problems, err := rules.Lint(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	fmt.Printf("%d rules OK, %d problems\n", valid, len(problems))
+	return len(problems) == 0
+}
+
+func writeGraph(cfg *cmd.Config, bigipConfig *parser.BigIPConfig) error {
+	g := graph.Build(bigipConfig)
+
+	if cfg.GraphPartition != "" {
+		g = g.InPartition(cfg.GraphPartition)
+	}
+	if cfg.GraphRoot != "" {
+		roots := g.Find(cfg.GraphRoot)
+		if len(roots) == 0 {
+			return fmt.Errorf("graph: object %s not found in configuration", cfg.GraphRoot)
+		}
+		ids := make([]string, len(roots))
+		for i, root := range roots {
+			ids[i] = root.ID
+		}
+		g = g.Around(ids...)
+	}
+
+	writer := output.NewWriter(cfg.OutputPath, cfg.Stdout)
+	if err := writer.WriteGraph(g, cfg.GraphFormat); err != nil {
+		return err
+	}
+
+	if !cfg.Stdout {
+		log.Printf("Dependency graph written to: %s", cfg.OutputPath)
+	}
+
+	return nil
+}
+
 func runDistributedMode(ctx context.Context) error {
 	storage, err := minio.New(interfaces.StorageConfig{
 		Endpoint:  os.Getenv("ENDPOINT"),
@@ -138,7 +217,12 @@ func runDistributedMode(ctx context.Context) error {
 		}
 	}
 
+	profiles, err := parser.LoadProfiles(os.Getenv("LOG_PROFILES"))
+	if err != nil {
+		return err
+	}
 	p := parser.NewParser(parser.DateParseOptions{})
+	p.SetProfiles(profiles)
 
 	proc, err := processor.New(processor.Config{
 		Storage:  storage,
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"goqkview/analyzer"
+	"goqkview/graph"
 )
 
 type Writer struct {
@@ -53,43 +55,123 @@ func (w *Writer) WriteToWriter(result *analyzer.AnalysisResult, writer io.Writer
 	return encoder.Encode(output)
 }
 
+// WriteGraph writes the dependency graph as DOT or node-link JSON.
+func (w *Writer) WriteGraph(g *graph.Graph, format string) error {
+	write := g.WriteJSON
+	if format == "dot" {
+		write = g.WriteDOT
+	}
+
+	if w.stdout {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(w.path)
+	if err != nil {
+		return fmt.Errorf("output: failed to create file %s: %w", w.path, err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+	return nil
+}
+
 type JSONOutput struct {
-	Summary         analyzer.Summary              `json:"summary"`
-	ErrorTimeline   []analyzer.TimelineEntry      `json:"errorTimeline"`
-	SSLFindings     []analyzer.SSLFinding         `json:"sslFindings"`
-	TopErrors       []TopErrorJSON                `json:"topErrors"`
-	Recommendations []analyzer.Recommendation     `json:"recommendations"`
-	VirtualServers  []analyzer.VirtualServerInfo  `json:"virtualServers"`
-	EntryLogs       []analyzer.EntryLog           `json:"entryLogs"`
+	Device              *analyzer.Device             `json:"device"`
+	ReferenceTime       string                       `json:"referenceTime"`
+	ReferenceTimeSource string                       `json:"referenceTimeSource"`
+	Summary             analyzer.Summary             `json:"summary"`
+	TimelineResolution  string                       `json:"timelineResolution"`
+	ErrorTimeline       []analyzer.TimelineEntry     `json:"errorTimeline"`
+	Incidents           []analyzer.Incident          `json:"incidents"`
+	ConfigChanges       []analyzer.ConfigChange      `json:"configChanges"`
+	SSLFindings         []analyzer.SSLFinding        `json:"sslFindings"`
+	RuleFindings        []analyzer.RuleFinding       `json:"ruleFindings"`
+	TopErrors           []TopErrorJSON               `json:"topErrors"`
+	Recommendations     []analyzer.Recommendation    `json:"recommendations"`
+	VirtualServers      []analyzer.VirtualServerInfo `json:"virtualServers"`
+	MemberHistory       []analyzer.MemberHistory     `json:"memberHistory"`
+	Nodes               []analyzer.NodeHealth        `json:"nodes"`
+	IRules              []analyzer.IRuleReport       `json:"iRules"`
+	ConfigHygiene       analyzer.ConfigHygiene       `json:"configHygiene"`
+	EntryLogs           []analyzer.EntryLog          `json:"entryLogs"`
 }
 
 type TopErrorJSON struct {
-	Message      string `json:"message"`
-	Count        int    `json:"count"`
-	LastOccurred string `json:"lastOccurred"`
+	Message       string     `json:"message"`
+	Count         int        `json:"count"`
+	FirstOccurred string     `json:"firstOccurred"`
+	LastOccurred  string     `json:"lastOccurred"`
+	Template      string     `json:"template"`
+	SampleParams  [][]string `json:"sampleParams,omitempty"`
+	MessageID     string     `json:"messageId,omitempty"`
+	Component     string     `json:"component,omitempty"`
+	Meaning       string     `json:"meaning,omitempty"`
+	Severity      string     `json:"severity,omitempty"`
+	Action        string     `json:"action,omitempty"`
+
+	RatePerHour    float64                `json:"ratePerHour"`
+	Files          []string               `json:"files"`
+	Hosts          []string               `json:"hosts"`
+	VirtualServers []string               `json:"virtualServers"`
+	Pools          []string               `json:"pools"`
+	Members        []string               `json:"members"`
+	Samples        []analyzer.ErrorSample `json:"samples"`
 }
 
 func (w *Writer) toJSONFormat(result *analyzer.AnalysisResult) JSONOutput {
 	topErrors := make([]TopErrorJSON, len(result.TopErrors))
 	for i, e := range result.TopErrors {
-		lastOccurred := e.LastOccurred.Format("2006-01-02T15:04:05Z")
-		if e.LastOccurred.IsZero() {
-			lastOccurred = ""
-		}
 		topErrors[i] = TopErrorJSON{
-			Message:      e.Message,
-			Count:        e.Count,
-			LastOccurred: lastOccurred,
+			Message:       e.Message,
+			Count:         e.Count,
+			FirstOccurred: formatTime(e.FirstOccurred),
+			LastOccurred:  formatTime(e.LastOccurred),
+			Template:      e.Template,
+			SampleParams:  e.SampleParams,
+			MessageID:     e.MessageID,
+			Component:     e.Component,
+			Meaning:       e.Meaning,
+			Severity:      e.Severity,
+			Action:        e.Action,
+
+			RatePerHour:    e.RatePerHour,
+			Files:          e.Files,
+			Hosts:          e.Hosts,
+			VirtualServers: e.VirtualServers,
+			Pools:          e.Pools,
+			Members:        e.Members,
+			Samples:        e.Samples,
 		}
 	}
 
 	return JSONOutput{
-		Summary:         result.Summary,
-		ErrorTimeline:   result.ErrorTimeline,
-		SSLFindings:     result.SSLFindings,
-		TopErrors:       topErrors,
-		Recommendations: result.Recommendations,
-		VirtualServers:  result.VirtualServers,
-		EntryLogs:       result.EntryLogs,
+		Device:              result.Device,
+		ReferenceTime:       result.ReferenceTime,
+		ReferenceTimeSource: result.ReferenceTimeSource,
+		Summary:             result.Summary,
+		TimelineResolution:  result.TimelineResolution,
+		ErrorTimeline:       result.ErrorTimeline,
+		Incidents:           result.Incidents,
+		ConfigChanges:       result.ConfigChanges,
+		SSLFindings:         result.SSLFindings,
+		RuleFindings:        result.RuleFindings,
+		TopErrors:           topErrors,
+		Recommendations:     result.Recommendations,
+		VirtualServers:      result.VirtualServers,
+		MemberHistory:       result.MemberHistory,
+		Nodes:               result.Nodes,
+		IRules:              result.IRules,
+		ConfigHygiene:       result.ConfigHygiene,
+		EntryLogs:           result.EntryLogs,
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
+	return t.Format("2006-01-02T15:04:05Z")
 }
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"goqkview/interfaces"
+)
+
+// BIG-IP writes three kinds of records to /var/log/audit:
+//
+//	tmsh[8010]: 01420002:5: AUDIT - pid=8010 user=admin folder=/Common module=(tmos)# status=[Command OK] cmd_data=modify ltm pool app_pool ...
+//	mcpd[5511]: 01070417:5: AUDIT - client tmsh-pid-8010, user admin - transaction #91-2 - object 0 - modify { pool { pool_name "/Common/app_pool" ... } } [Status=Command OK]
+//	httpd[4120]: 01070417:5: AUDIT - user admin - RAW: httpd(pam_audit): User=admin tty=(unknown) host=10.1.1.5 failures=0 attempts=1 successes=1 ...
+//
+// The first is the command as typed, the second what mcpd committed, the
+// third a login.
+var (
+	auditCommandPattern     = regexp.MustCompile(`\bcmd_data=(.*)$`)
+	auditStatusPattern      = regexp.MustCompile(`\bstatus=\[([^\]]*)\]`)
+	auditFolderPattern      = regexp.MustCompile(`\bfolder=(\S+)`)
+	auditUserPattern        = regexp.MustCompile(`(?i)\buser[= ]([^\s(,]+)`)
+	auditTransactionPattern = regexp.MustCompile(
+		`^(?:client (.+?), )?user (\S+) - transaction #\S+ - object \d+ - (\w+) (.*?)(?: \[Status=(.*)\])?$`)
+	auditClassPattern = regexp.MustCompile(`^\{\s*(\w+)`)
+	auditNamePattern  = regexp.MustCompile(`"(/[^"]+)"`)
+	auditLoginPattern = regexp.MustCompile(`RAW: ([\w-]+)\(pam_audit\):`)
+	auditHostPattern  = regexp.MustCompile(`\bhost=([^\s,]+)`)
+	auditCountPattern = regexp.MustCompile(`\b(failures|successes)=(\d+)`)
+)
+
+// changeActions are the tmsh verbs and mcpd operations that alter the configuration.
+var changeActions = map[string]bool{
+	"create":  true,
+	"modify":  true,
+	"delete":  true,
+	"mv":      true,
+	"edit":    true,
+	"load":    true,
+	"install": true,
+	"restore": true,
+}
+
+// tmsh modules whose objects live in partitions; a relative name is
+// resolved against the record's folder.
+var partitionedModules = map[string]bool{
+	"ltm": true, "gtm": true, "net": true, "security": true, "apm": true, "asm": true, "pem": true,
+}
+
+// tmsh modules and the object types that take a further subtype, as in
+// "ltm profile http" or "sys file ssl-cert".
+var (
+	tmshModules = map[string]bool{
+		"ltm": true, "gtm": true, "net": true, "sys": true, "auth": true, "cm": true,
+		"security": true, "apm": true, "asm": true, "pem": true, "analytics": true, "ilx": true,
+	}
+	tmshSubtyped = map[string]bool{
+		"profile": true, "monitor": true, "persistence": true, "data-group": true,
+		"file": true, "crypto": true, "tunnels": true, "firewall": true, "wideip": true,
+	}
+)
+
+// ParseAudit reads an audit record from the message of a syslog line written
+// by process. Lines that are not audit records are reported as not matching.
+func ParseAudit(process, message string) (*interfaces.AuditRecord, bool) {
+	message, ok := strings.CutPrefix(message, "AUDIT - ")
+	if !ok {
+		return nil, false
+	}
+
+	if m := auditCommandPattern.FindStringSubmatch(message); m != nil {
+		record := &interfaces.AuditRecord{
+			Interface: auditInterface(process),
+			Command:   strings.TrimSpace(m[1]),
+		}
+		if u := auditUserPattern.FindStringSubmatch(message); u != nil {
+			record.User = u[1]
+		}
+		if s := auditStatusPattern.FindStringSubmatch(message); s != nil {
+			record.Result = s[1]
+		}
+		folder := ""
+		if f := auditFolderPattern.FindStringSubmatch(message); f != nil {
+			folder = f[1]
+		}
+		record.Action, record.Object = tmshObject(record.Command, folder)
+		record.Success = record.Result == "Command OK"
+		record.Change = changeActions[record.Action]
+		return record, true
+	}
+
+	if m := auditTransactionPattern.FindStringSubmatch(message); m != nil {
+		client := m[1]
+		if client == "" {
+			client = process
+		}
+		record := &interfaces.AuditRecord{
+			User:      m[2],
+			Interface: auditInterface(client),
+			Action:    m[3],
+			Command:   m[3] + " " + m[4],
+			Result:    m[5],
+		}
+		if c := auditClassPattern.FindStringSubmatch(m[4]); c != nil {
+			record.Object = c[1]
+			if n := auditNamePattern.FindStringSubmatch(m[4]); n != nil {
+				record.Object += " " + n[1]
+			}
+		}
+		record.Success = record.Result == "Command OK"
+		record.Change = changeActions[record.Action]
+		return record, true
+	}
+
+	if m := auditLoginPattern.FindStringSubmatch(message); m != nil {
+		record := &interfaces.AuditRecord{
+			Interface: auditInterface(m[1]),
+			Action:    "login",
+			Success:   true,
+		}
+		if u := auditUserPattern.FindStringSubmatch(message); u != nil {
+			record.User = u[1]
+		}
+		if h := auditHostPattern.FindStringSubmatch(message); h != nil {
+			record.Address = h[1]
+		}
+		for _, c := range auditCountPattern.FindAllStringSubmatch(message, -1) {
+			n, _ := strconv.Atoi(c[2])
+			if (c[1] == "failures" && n > 0) || (c[1] == "successes" && n == 0) {
+				record.Success = false
+			}
+		}
+		record.Result = "login succeeded"
+		if !record.Success {
+			record.Result = "login failed"
+		}
+		return record, true
+	}
+
+	return nil, false
+}
+
+// auditInterface names the interface behind a process or mcpd client such
+// as "tmsh-pid-8010", "icrd_child" or "GUI".
+func auditInterface(client string) string {
+	name := strings.ToLower(client)
+	if i := strings.Index(name, "-pid-"); i >= 0 {
+		name = name[:i]
+	}
+	switch {
+	case strings.HasPrefix(name, "tmsh"):
+		return "tmsh"
+	case strings.Contains(name, "rest"), strings.HasPrefix(name, "icrd"):
+		return "iControl REST"
+	case strings.HasPrefix(name, "icontrol"), strings.Contains(name, "soap"):
+		return "iControl SOAP"
+	case name == "gui", strings.HasPrefix(name, "tmui"), name == "httpd":
+		return "GUI"
+	case name == "sshd", name == "ssh":
+		return "ssh"
+	}
+	return client
+}
+
+// tmshObject splits a tmsh command into its verb and the object it
+// targets: "modify ltm pool app_pool members add { ... }" in folder /Common
+// is modify on "ltm pool /Common/app_pool".
+func tmshObject(command, folder string) (action, object string) {
+	tokens := strings.Fields(command)
+	if len(tokens) == 0 {
+		return "", ""
+	}
+	action = tokens[0]
+	if len(tokens) < 3 || !tmshModules[tokens[1]] || strings.HasPrefix(tokens[2], "{") {
+		return action, ""
+	}
+
+	parts := tokens[1:3]
+	i := 3
+	if tmshSubtyped[tokens[2]] && i < len(tokens) && !strings.HasPrefix(tokens[i], "{") {
+		parts = tokens[1:4]
+		i++
+	}
+	object = strings.Join(parts, " ")
+	if i < len(tokens) && !strings.HasPrefix(tokens[i], "{") {
+		name := tokens[i]
+		if !strings.HasPrefix(name, "/") && partitionedModules[tokens[1]] && strings.HasPrefix(folder, "/") {
+			name = strings.TrimSuffix(folder, "/") + "/" + name
+		}
+		object += " " + name
+	}
+	return action, object
+}
@@ -5,32 +5,146 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type BigIPConfig struct {
+	VirtualServers    map[string]*VirtualServerConfig
+	Pools             map[string]*PoolConfig
+	ClientSSLProfiles map[string]*ClientSSLProfile
+	Nodes             map[string]*NodeConfig
+	Rules             map[string]*RuleConfig
+	DataGroups        map[string]*DataGroupConfig
+	Monitors          map[string]*MonitorConfig
+	Profiles          map[string]*ProfileConfig // All ltm profiles, client-ssl included
+
+	// The maps above are keyed by short name, so of /Common/app and
+	// /Tenant/app they keep the last one parsed; ByPath keeps both.
+	ByPath ObjectsByPath
+
+	// Network objects, see ParseNetworkConfig
+	SelfIPs          map[string]*SelfIP
+	VLANs            map[string]*VLANConfig
+	RouteDomains     map[int]*RouteDomain
+	VirtualAddresses map[string]*VirtualAddress
+}
+
+// ObjectsByPath holds the LTM objects keyed by full path, e.g. /Common/app.
+type ObjectsByPath struct {
 	VirtualServers map[string]*VirtualServerConfig
 	Pools          map[string]*PoolConfig
+	Nodes          map[string]*NodeConfig
+	Rules          map[string]*RuleConfig
+	Monitors       map[string]*MonitorConfig
+	Profiles       map[string]*ProfileConfig
+
+	ClientSSLProfiles map[string]*ClientSSLProfile
+	DataGroups        map[string]*DataGroupConfig
 }
 
 type VirtualServerConfig struct {
-	Name        string
-	Pool        string // Pool reference (cleaned name)
-	Disabled    bool
-	Destination string
+	Name            string
+	Partition       string
+	Path            string // Full path, e.g. /Common/vs_web
+	Pool            string // Pool reference (cleaned name)
+	PoolPath        string // Full path of the pool
+	Disabled        bool
+	Destination     string // address:port with optional %route-domain, partition stripped
+	DestinationPath string // Destination as written, with partition and folder
+	Mask            string
+	Source          string
+	IPProtocol      string
+	Profiles        []string // Attached profiles (cleaned names)
+	Rules           []string // Attached iRules in evaluation order (cleaned names)
+
+	ProfilePaths []string // Full paths of Profiles
+	RulePaths    []string // Full paths of Rules, same order
+}
+
+type RuleConfig struct {
+	Name      string
+	Partition string
+	Path      string
+	Body      string // TCL source between the outer braces
+}
+
+type MonitorConfig struct {
+	Name         string
+	Partition    string
+	Path         string
+	Type         string // http, https, tcp, icmp, ...
+	DefaultsFrom string
+
+	DefaultsFromPath string
+}
+
+type ProfileConfig struct {
+	Name         string
+	Partition    string
+	Path         string
+	Type         string // http, tcp, client-ssl, ...
+	DefaultsFrom string
+
+	DefaultsFromPath string
+}
+
+type DataGroupConfig struct {
+	Name      string
+	Partition string
+	Path      string
+	Type      string // string, ip, integer
+	External  bool
+}
+
+type ClientSSLProfile struct {
+	Name         string
+	Path         string
+	DefaultsFrom string
+	Ciphers      string // Raw cipher string, empty when inherited
+	Cert         string
+	Key          string
+	Options      []string
+
+	DefaultsFromPath string
+	CertPath         string
+	KeyPath          string
 }
 
 type PoolConfig struct {
-	Name    string
-	Members []PoolMember
-	Monitor string
+	Name      string
+	Partition string
+	Path      string
+	Members   []PoolMember
+	Monitor   string
+	Monitors  []string // Every monitor in the rule, e.g. "http and tcp"
+
+	MonitorPaths []string // Full paths of Monitors
+}
+
+type NodeConfig struct {
+	Name            string
+	Partition       string
+	Path            string
+	Address         string
+	Monitor         string
+	Monitors        []string
+	MonitorPaths    []string
+	Ratio           int
+	ConnectionLimit int
+	Disabled        bool // session user-disabled
+	Down            bool // state user-down
 }
 
 type PoolMember struct {
 	Name     string // Node:port
+	Path     string // Full path of the member, e.g. /Common/10.0.0.5:80
 	Address  string
 	Disabled bool // session user-disabled
 	Down     bool // state user-down
+
+	Status       MemberStatus // Monitor status from runtime data, if present
+	StatusReason string
 }
 
 type parseState int
@@ -41,6 +155,14 @@ const (
 	statePool
 	statePoolMembers
 	stateMember
+	stateVirtualProfiles
+	stateClientSSL
+	stateNode
+	stateVirtualRules
+	stateRule
+	stateDataGroup
+	stateMonitor
+	stateProfile
 )
 
 var (
@@ -50,7 +172,27 @@ var (
 	poolRefPattern = regexp.MustCompile(`^\s*pool\s+(/\S+)`)
 	destPattern    = regexp.MustCompile(`^\s*destination\s+(/\S+)`)
 	addressPattern = regexp.MustCompile(`^\s*address\s+(\S+)`)
-	monitorPattern = regexp.MustCompile(`^\s*monitor\s+(/\S+)`)
+
+	clientSSLPattern    = regexp.MustCompile(`^ltm profile client-ssl\s+(/\S+)\s*\{`)
+	profileEntryPattern = regexp.MustCompile(`^\s*(/\S+)\s*\{`)
+	defaultsFromPattern = regexp.MustCompile(`^\s*defaults-from\s+(/\S+)`)
+	ciphersPattern      = regexp.MustCompile(`^\s*ciphers\s+(.+)$`)
+	certPattern         = regexp.MustCompile(`^\s*cert\s+(/\S+)`)
+	keyPattern          = regexp.MustCompile(`^\s*key\s+(/\S+)`)
+	optionsPattern      = regexp.MustCompile(`^\s*options\s*\{([^}]*)\}`)
+
+	nodePattern      = regexp.MustCompile(`^ltm node\s+(/\S+)\s*\{`)
+	ratioPattern     = regexp.MustCompile(`^\s*ratio\s+(\d+)`)
+	connLimitPattern = regexp.MustCompile(`^\s*connection-limit\s+(\d+)`)
+
+	rulePattern      = regexp.MustCompile(`^ltm rule\s+(/\S+)\s*\{`)
+	dataGroupPattern = regexp.MustCompile(`^ltm data-group\s+(internal|external)\s+(/\S+)\s*\{`)
+	typePattern      = regexp.MustCompile(`^\s*type\s+(\S+)`)
+	ruleRefPattern   = regexp.MustCompile(`/[^\s{}]+`)
+
+	ltmMonitorPattern = regexp.MustCompile(`^ltm monitor\s+(\S+)\s+(/\S+)\s*\{`)
+	ltmProfilePattern = regexp.MustCompile(`^ltm profile\s+(\S+)\s+(/\S+)\s*\{`)
+	vsAttrPattern     = regexp.MustCompile(`^\s*(mask|source|ip-protocol)\s+(\S+)\s*$`)
 )
 
 func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
@@ -61,28 +203,76 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 	defer file.Close()
 
 	config := &BigIPConfig{
-		VirtualServers: make(map[string]*VirtualServerConfig),
-		Pools:          make(map[string]*PoolConfig),
+		VirtualServers:    make(map[string]*VirtualServerConfig),
+		Pools:             make(map[string]*PoolConfig),
+		ClientSSLProfiles: make(map[string]*ClientSSLProfile),
+		Nodes:             make(map[string]*NodeConfig),
+		Rules:             make(map[string]*RuleConfig),
+		DataGroups:        make(map[string]*DataGroupConfig),
+		Monitors:          make(map[string]*MonitorConfig),
+		Profiles:          make(map[string]*ProfileConfig),
+		ByPath: ObjectsByPath{
+			VirtualServers: make(map[string]*VirtualServerConfig),
+			Pools:          make(map[string]*PoolConfig),
+			Nodes:          make(map[string]*NodeConfig),
+			Rules:          make(map[string]*RuleConfig),
+			Monitors:       make(map[string]*MonitorConfig),
+			Profiles:       make(map[string]*ProfileConfig),
+
+			ClientSSLProfiles: make(map[string]*ClientSSLProfile),
+			DataGroups:        make(map[string]*DataGroupConfig),
+		},
 	}
+	config.initNetwork()
 
 	scanner := bufio.NewScanner(file)
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
 
 	var state parseState
-	var braceDepth int          // Total brace depth
-	var blockStartDepth int     // Depth when we entered current block
-	var membersStartDepth int   // Depth when we entered members block
-	var memberStartDepth int    // Depth when we entered individual member
+	var braceDepth int         // Total brace depth
+	var blockStartDepth int    // Depth when we entered current block
+	var membersStartDepth int  // Depth when we entered members block
+	var memberStartDepth int   // Depth when we entered individual member
+	var profilesStartDepth int // Depth when we entered virtual profiles block
+	var rulesStartDepth int    // Depth when we entered virtual rules block
 
 	var currentVS *VirtualServerConfig
 	var currentPool *PoolConfig
 	var currentMember *PoolMember
+	var currentClientSSL *ClientSSLProfile
+	var currentNode *NodeConfig
+	var currentRule *RuleConfig
+	var ruleBody []string
+	var currentDataGroup *DataGroupConfig
+	var currentMonitor *MonitorConfig
+	var currentProfile *ProfileConfig
 
 	for scanner.Scan() {
 		line := scanner.Text()
 		trimmed := strings.TrimSpace(line)
 
+		// iRule bodies are TCL: keep them verbatim, comments included, and
+		// only track braces until the rule's closing brace.
+		if state == stateRule {
+			if !strings.HasPrefix(trimmed, "#") {
+				braceDepth += CountTCLBraces(trimmed)
+			}
+			if braceDepth <= blockStartDepth {
+				if currentRule != nil {
+					currentRule.Body = strings.Join(ruleBody, "\n")
+					config.Rules[currentRule.Name] = currentRule
+					config.ByPath.Rules[currentRule.Path] = currentRule
+				}
+				currentRule = nil
+				ruleBody = nil
+				state = stateNone
+				continue
+			}
+			ruleBody = append(ruleBody, line)
+			continue
+		}
+
 		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
 			continue
 		}
@@ -94,8 +284,8 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 			state = stateVirtual
 			blockStartDepth = braceDepth
 			braceDepth += openBraces
-			name := cleanName(matches[1])
-			currentVS = &VirtualServerConfig{Name: name}
+			name := CleanName(matches[1])
+			currentVS = &VirtualServerConfig{Name: name, Partition: PartitionOf(matches[1]), Path: fullPath(matches[1])}
 			continue
 		}
 
@@ -103,11 +293,125 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 			state = statePool
 			blockStartDepth = braceDepth
 			braceDepth += openBraces
-			name := cleanName(matches[1])
-			currentPool = &PoolConfig{Name: name, Members: []PoolMember{}}
+			name := CleanName(matches[1])
+			currentPool = &PoolConfig{Name: name, Partition: PartitionOf(matches[1]), Path: fullPath(matches[1]), Members: []PoolMember{}}
+			continue
+		}
+
+		if matches := clientSSLPattern.FindStringSubmatch(line); matches != nil {
+			state = stateClientSSL
+			blockStartDepth = braceDepth
+			braceDepth += openBraces
+			name := CleanName(matches[1])
+			currentClientSSL = &ClientSSLProfile{Name: name, Path: fullPath(matches[1])}
+			currentProfile = &ProfileConfig{Name: name, Partition: PartitionOf(matches[1]), Path: fullPath(matches[1]), Type: "client-ssl"}
+			continue
+		}
+
+		if matches := ltmProfilePattern.FindStringSubmatch(line); matches != nil {
+			state = stateProfile
+			blockStartDepth = braceDepth
+			braceDepth += openBraces
+			currentProfile = &ProfileConfig{Name: CleanName(matches[2]), Partition: PartitionOf(matches[2]), Path: fullPath(matches[2]), Type: matches[1]}
+			if braceDepth <= blockStartDepth {
+				config.Profiles[currentProfile.Name] = currentProfile
+				config.ByPath.Profiles[currentProfile.Path] = currentProfile
+				currentProfile = nil
+				state = stateNone
+			}
 			continue
 		}
 
+		if matches := ltmMonitorPattern.FindStringSubmatch(line); matches != nil {
+			state = stateMonitor
+			blockStartDepth = braceDepth
+			braceDepth += openBraces
+			currentMonitor = &MonitorConfig{Name: CleanName(matches[2]), Partition: PartitionOf(matches[2]), Path: fullPath(matches[2]), Type: matches[1]}
+			if braceDepth <= blockStartDepth {
+				config.Monitors[currentMonitor.Name] = currentMonitor
+				config.ByPath.Monitors[currentMonitor.Path] = currentMonitor
+				currentMonitor = nil
+				state = stateNone
+			}
+			continue
+		}
+
+		if matches := nodePattern.FindStringSubmatch(line); matches != nil {
+			state = stateNode
+			blockStartDepth = braceDepth
+			braceDepth += openBraces
+			name := CleanName(matches[1])
+			currentNode = &NodeConfig{Name: name, Partition: PartitionOf(matches[1]), Path: fullPath(matches[1])}
+			continue
+		}
+
+		if matches := rulePattern.FindStringSubmatch(line); matches != nil {
+			state = stateRule
+			blockStartDepth = braceDepth
+			braceDepth += CountTCLBraces(trimmed)
+			currentRule = &RuleConfig{Name: CleanName(matches[1]), Partition: PartitionOf(matches[1]), Path: fullPath(matches[1])}
+			if braceDepth <= blockStartDepth {
+				config.Rules[currentRule.Name] = currentRule
+				config.ByPath.Rules[currentRule.Path] = currentRule
+				currentRule = nil
+				state = stateNone
+			}
+			continue
+		}
+
+		if matches := dataGroupPattern.FindStringSubmatch(line); matches != nil {
+			state = stateDataGroup
+			blockStartDepth = braceDepth
+			braceDepth += openBraces
+			currentDataGroup = &DataGroupConfig{
+				Name:      CleanName(matches[2]),
+				Partition: PartitionOf(matches[2]),
+				Path:      fullPath(matches[2]),
+				External:  matches[1] == "external",
+			}
+			continue
+		}
+
+		if state == stateVirtual && strings.HasPrefix(trimmed, "rules {") {
+			state = stateVirtualRules
+			rulesStartDepth = braceDepth
+			braceDepth += openBraces - closeBraces
+			if currentVS != nil {
+				for _, ref := range ruleRefPattern.FindAllString(trimmed, -1) {
+					currentVS.Rules = append(currentVS.Rules, CleanName(ref))
+					currentVS.RulePaths = append(currentVS.RulePaths, fullPath(ref))
+				}
+			}
+			if braceDepth <= rulesStartDepth {
+				state = stateVirtual
+			}
+			continue
+		}
+
+		if state == stateVirtualRules && currentVS != nil {
+			for _, ref := range ruleRefPattern.FindAllString(trimmed, -1) {
+				currentVS.Rules = append(currentVS.Rules, CleanName(ref))
+				currentVS.RulePaths = append(currentVS.RulePaths, fullPath(ref))
+			}
+		}
+
+		if state == stateVirtual && strings.HasPrefix(trimmed, "profiles {") {
+			state = stateVirtualProfiles
+			profilesStartDepth = braceDepth
+			braceDepth += openBraces - closeBraces
+			if braceDepth <= profilesStartDepth {
+				state = stateVirtual
+			}
+			continue
+		}
+
+		if state == stateVirtualProfiles && braceDepth == profilesStartDepth+1 {
+			if matches := profileEntryPattern.FindStringSubmatch(line); matches != nil && currentVS != nil {
+				currentVS.Profiles = append(currentVS.Profiles, CleanName(matches[1]))
+				currentVS.ProfilePaths = append(currentVS.ProfilePaths, fullPath(matches[1]))
+			}
+		}
+
 		if state == statePool && strings.Contains(trimmed, "members {") {
 			state = statePoolMembers
 			membersStartDepth = braceDepth
@@ -120,8 +424,8 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 				state = stateMember
 				memberStartDepth = braceDepth
 				braceDepth += openBraces
-				name := cleanName(matches[1])
-				currentMember = &PoolMember{Name: name}
+				name := CleanName(matches[1])
+				currentMember = &PoolMember{Name: name, Path: fullPath(matches[1])}
 				continue
 			}
 		}
@@ -142,9 +446,68 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 				state = statePool
 			}
 
+			if state == stateVirtualProfiles && braceDepth <= profilesStartDepth {
+				state = stateVirtual
+			}
+
+			if state == stateVirtualRules && braceDepth <= rulesStartDepth {
+				state = stateVirtual
+			}
+
+			if state == stateDataGroup && braceDepth <= blockStartDepth {
+				if currentDataGroup != nil {
+					config.DataGroups[currentDataGroup.Name] = currentDataGroup
+					config.ByPath.DataGroups[currentDataGroup.Path] = currentDataGroup
+				}
+				currentDataGroup = nil
+				state = stateNone
+			}
+
+			if state == stateNode && braceDepth <= blockStartDepth {
+				if currentNode != nil {
+					config.Nodes[currentNode.Name] = currentNode
+					config.ByPath.Nodes[currentNode.Path] = currentNode
+				}
+				currentNode = nil
+				state = stateNone
+			}
+
+			if state == stateClientSSL && braceDepth <= blockStartDepth {
+				if currentClientSSL != nil {
+					config.ClientSSLProfiles[currentClientSSL.Name] = currentClientSSL
+					config.ByPath.ClientSSLProfiles[currentClientSSL.Path] = currentClientSSL
+					currentProfile.DefaultsFrom = currentClientSSL.DefaultsFrom
+					currentProfile.DefaultsFromPath = currentClientSSL.DefaultsFromPath
+					config.Profiles[currentProfile.Name] = currentProfile
+					config.ByPath.Profiles[currentProfile.Path] = currentProfile
+				}
+				currentClientSSL = nil
+				currentProfile = nil
+				state = stateNone
+			}
+
+			if state == stateProfile && braceDepth <= blockStartDepth {
+				if currentProfile != nil {
+					config.Profiles[currentProfile.Name] = currentProfile
+					config.ByPath.Profiles[currentProfile.Path] = currentProfile
+				}
+				currentProfile = nil
+				state = stateNone
+			}
+
+			if state == stateMonitor && braceDepth <= blockStartDepth {
+				if currentMonitor != nil {
+					config.Monitors[currentMonitor.Name] = currentMonitor
+					config.ByPath.Monitors[currentMonitor.Path] = currentMonitor
+				}
+				currentMonitor = nil
+				state = stateNone
+			}
+
 			if state == statePool && braceDepth <= blockStartDepth {
 				if currentPool != nil {
 					config.Pools[currentPool.Name] = currentPool
+					config.ByPath.Pools[currentPool.Path] = currentPool
 				}
 				currentPool = nil
 				state = stateNone
@@ -153,6 +516,7 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 			if state == stateVirtual && braceDepth <= blockStartDepth {
 				if currentVS != nil {
 					config.VirtualServers[currentVS.Name] = currentVS
+					config.ByPath.VirtualServers[currentVS.Path] = currentVS
 				}
 				currentVS = nil
 				state = stateNone
@@ -164,18 +528,95 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 			case stateVirtual:
 				if currentVS != nil {
 					if matches := poolRefPattern.FindStringSubmatch(line); matches != nil {
-						currentVS.Pool = cleanName(matches[1])
+						currentVS.Pool = CleanName(matches[1])
+						currentVS.PoolPath = fullPath(matches[1])
 					} else if matches := destPattern.FindStringSubmatch(line); matches != nil {
-						currentVS.Destination = cleanName(matches[1])
+						currentVS.Destination = CleanName(matches[1])
+						currentVS.DestinationPath = fullPath(matches[1])
 					} else if trimmed == "disabled" {
 						currentVS.Disabled = true
+					} else if matches := vsAttrPattern.FindStringSubmatch(line); matches != nil && braceDepth == blockStartDepth+1 {
+						switch matches[1] {
+						case "mask":
+							currentVS.Mask = matches[2]
+						case "source":
+							currentVS.Source = matches[2]
+						case "ip-protocol":
+							currentVS.IPProtocol = matches[2]
+						}
 					}
 				}
 
 			case statePool, statePoolMembers:
 				if currentPool != nil {
-					if matches := monitorPattern.FindStringSubmatch(line); matches != nil {
-						currentPool.Monitor = cleanName(matches[1])
+					if strings.HasPrefix(trimmed, "monitor ") {
+						currentPool.MonitorPaths = monitorRefs(trimmed)
+						currentPool.Monitors = cleanNames(currentPool.MonitorPaths)
+						if len(currentPool.Monitors) > 0 {
+							currentPool.Monitor = currentPool.Monitors[0]
+						}
+					}
+				}
+
+			case stateClientSSL:
+				if currentClientSSL != nil {
+					if matches := defaultsFromPattern.FindStringSubmatch(line); matches != nil {
+						currentClientSSL.DefaultsFrom = CleanName(matches[1])
+						currentClientSSL.DefaultsFromPath = fullPath(matches[1])
+					} else if matches := ciphersPattern.FindStringSubmatch(line); matches != nil {
+						currentClientSSL.Ciphers = strings.Trim(strings.TrimSpace(matches[1]), `"`)
+					} else if matches := certPattern.FindStringSubmatch(line); matches != nil && currentClientSSL.Cert == "" {
+						currentClientSSL.Cert = CleanName(matches[1])
+						currentClientSSL.CertPath = fullPath(matches[1])
+					} else if matches := keyPattern.FindStringSubmatch(line); matches != nil && currentClientSSL.Key == "" {
+						currentClientSSL.Key = CleanName(matches[1])
+						currentClientSSL.KeyPath = fullPath(matches[1])
+					} else if matches := optionsPattern.FindStringSubmatch(line); matches != nil {
+						currentClientSSL.Options = strings.Fields(matches[1])
+					}
+				}
+
+			case stateMonitor:
+				if currentMonitor != nil {
+					if matches := defaultsFromPattern.FindStringSubmatch(line); matches != nil {
+						currentMonitor.DefaultsFrom = CleanName(matches[1])
+						currentMonitor.DefaultsFromPath = fullPath(matches[1])
+					}
+				}
+
+			case stateProfile:
+				if currentProfile != nil && braceDepth == blockStartDepth+1 {
+					if matches := defaultsFromPattern.FindStringSubmatch(line); matches != nil {
+						currentProfile.DefaultsFrom = CleanName(matches[1])
+						currentProfile.DefaultsFromPath = fullPath(matches[1])
+					}
+				}
+
+			case stateDataGroup:
+				if currentDataGroup != nil && currentDataGroup.Type == "" {
+					if matches := typePattern.FindStringSubmatch(line); matches != nil {
+						currentDataGroup.Type = matches[1]
+					}
+				}
+
+			case stateNode:
+				if currentNode != nil {
+					if matches := addressPattern.FindStringSubmatch(line); matches != nil {
+						currentNode.Address = matches[1]
+					} else if strings.HasPrefix(trimmed, "monitor ") {
+						currentNode.MonitorPaths = monitorRefs(trimmed)
+						currentNode.Monitors = cleanNames(currentNode.MonitorPaths)
+						if len(currentNode.Monitors) > 0 {
+							currentNode.Monitor = currentNode.Monitors[0]
+						}
+					} else if matches := ratioPattern.FindStringSubmatch(line); matches != nil {
+						currentNode.Ratio, _ = strconv.Atoi(matches[1])
+					} else if matches := connLimitPattern.FindStringSubmatch(line); matches != nil {
+						currentNode.ConnectionLimit, _ = strconv.Atoi(matches[1])
+					} else if strings.Contains(trimmed, "session user-disabled") {
+						currentNode.Disabled = true
+					} else if strings.Contains(trimmed, "state user-down") {
+						currentNode.Down = true
 					}
 				}
 
@@ -200,7 +641,42 @@ func ParseBigIPConfig(filePath string) (*BigIPConfig, error) {
 	return config, nil
 }
 
-func cleanName(fullName string) string {
+// monitorRefs extracts the full path of every monitor from a rule such as
+// "monitor /Common/http and /Common/tcp" or "monitor min 1 of { /Common/a /Common/b }".
+func monitorRefs(line string) []string {
+	return ruleRefPattern.FindAllString(line, -1)
+}
+
+func cleanNames(paths []string) []string {
+	var names []string
+	for _, path := range paths {
+		names = append(names, CleanName(path))
+	}
+	return names
+}
+
+// PartitionOf returns the administrative partition of a full path such as
+// /Common/app/vs, or an empty string for names without one.
+func PartitionOf(fullName string) string {
+	parts := strings.Split(fullName, "/")
+	if len(parts) >= 3 {
+		return parts[1]
+	}
+	return ""
+}
+
+// fullPath returns the full path of an object name; names without a
+// partition are in /Common.
+func fullPath(name string) string {
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+	return "/Common/" + name
+}
+
+// CleanName returns the object name of a full path such as /Common/app/vs,
+// without partition and folder.
+func CleanName(fullName string) string {
 	parts := strings.Split(fullName, "/")
 	if len(parts) >= 3 {
 		return parts[len(parts)-1]
@@ -208,16 +684,103 @@ func cleanName(fullName string) string {
 	return strings.TrimPrefix(fullName, "/")
 }
 
+// EffectiveCiphers follows the defaults-from chain of the client-ssl profile
+// at profilePath. Built-in parents live outside bigip.conf and use the
+// DEFAULT cipher string.
+func (c *BigIPConfig) EffectiveCiphers(profilePath string) string {
+	seen := make(map[string]bool)
+	for path := profilePath; path != "" && !seen[path]; {
+		seen[path] = true
+		profile, ok := c.ByPath.ClientSSLProfiles[path]
+		if !ok {
+			break
+		}
+		if profile.Ciphers != "" {
+			return profile.Ciphers
+		}
+		path = profile.DefaultsFromPath
+	}
+	return "DEFAULT"
+}
+
+// ResolvePath returns the full path a name written without one, as iRules
+// do, refers to: the object in partition when defined reports it exists,
+// else the one in /Common. Full paths are returned unchanged.
+func ResolvePath(name, partition string, defined func(path string) bool) string {
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+	if partition != "" && partition != "Common" {
+		if path := "/" + partition + "/" + name; defined(path) {
+			return path
+		}
+	}
+	return "/Common/" + name
+}
+
+// Available reports whether the member can take traffic. Unchecked members
+// count as available, matching how BIG-IP load balances to them.
+func (m PoolMember) Available() bool {
+	if m.Disabled || m.Down {
+		return false
+	}
+	return m.Status != MemberStatusDown && m.Status != MemberStatusForcedOffline
+}
+
+// NodeName returns the node a member points at. IPv6 members separate the
+// port with a dot (2001:db8::1.80), everything else with a colon.
+func (m PoolMember) NodeName() string {
+	sep := ":"
+	if strings.Count(m.Name, ":") > 1 {
+		sep = "."
+	}
+	if idx := strings.LastIndex(m.Name, sep); idx != -1 {
+		return m.Name[:idx]
+	}
+	return m.Name
+}
+
+// NodePath returns the full path of the node a member points at.
+func (m PoolMember) NodePath() string {
+	return fullPath(strings.TrimSuffix(m.Path, strings.TrimPrefix(m.Name, m.NodeName())))
+}
+
+func (m PoolMember) UnavailableReason() string {
+	switch {
+	case m.Status == MemberStatusForcedOffline:
+		return "forced offline"
+	case m.Down:
+		return "user-down"
+	case m.Disabled:
+		return "user-disabled"
+	case m.Status == MemberStatusDown && m.StatusReason != "":
+		return "monitor down: " + m.StatusReason
+	case m.Status == MemberStatusDown:
+		return "monitor down"
+	}
+	return ""
+}
+
 func (p *PoolConfig) GetActiveMembers() int {
 	active := 0
 	for _, m := range p.Members {
-		if !m.Disabled && !m.Down {
+		if m.Available() {
 			active++
 		}
 	}
 	return active
 }
 
+// HasRuntimeStatus reports whether any member carries monitor status.
+func (p *PoolConfig) HasRuntimeStatus() bool {
+	for _, m := range p.Members {
+		if m.Status != MemberStatusUnknown {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *PoolConfig) GetTotalMembers() int {
 	return len(p.Members)
 }
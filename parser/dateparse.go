@@ -2,6 +2,7 @@ package parser
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,14 +16,18 @@ var (
 	isoDatePattern = regexp.MustCompile(
 		`\b(\d{4})-(\d{2})-(\d{2})\s+(\d{2}:\d{2}:\d{2})\b`)
 
-	// Matches: "2023-10-24T13:00:00" or "2023-10-24T13:00:00Z" (RFC3339-like)
+	// Matches: "2023-10-24T13:00:00", "2023-10-24T13:00:00Z" or
+	// "2023-10-24T13:00:00.123-07:00" (RFC3339-like, offset kept)
 	rfc3339Pattern = regexp.MustCompile(
-		`\b(\d{4})-(\d{2})-(\d{2})T(\d{2}:\d{2}:\d{2})(?:Z|[+-]\d{2}:?\d{2})?\b`)
+		`\b(\d{4})-(\d{2})-(\d{2})T(\d{2}:\d{2}:\d{2})(?:\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
 
 	// Matches: "Oct 14 13:00:00" (without year - common syslog format)
 	dateWithoutYearPattern = regexp.MustCompile(
 		`\b(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\s+(\d{1,2})\s+(\d{2}:\d{2}:\d{2})\b`)
 
+	// Matches the "[SEVERE][102][" in front of a restjavad timestamp
+	javaHeaderPattern = regexp.MustCompile(`^\[[A-Z]+\]\[\d+\]\[`)
+
 	// Status pattern (case insensitive)
 	statusPattern = regexp.MustCompile(`(?i)\b(warning|error|severe|critical|notice)\b`)
 )
@@ -38,48 +43,137 @@ const (
 type DateParseOptions struct {
 	ReferenceTime time.Time
 	DefaultYear int
+	Location *time.Location // Device time zone for timestamps without an offset; nil means time.Local
+	Format string // Where the timestamp is: DateFormatAuto (default), DateFormatSyslog or DateFormatJava
+}
+
+func (o DateParseOptions) location() *time.Location {
+	if o.Location != nil {
+		return o.Location
+	}
+	return time.Local
 }
 
+// ParseDate returns the timestamp in the zone it was written in: its own
+// offset when it carries one, opts.Location otherwise. Year-less timestamps
+// get the latest year that does not put them after the reference time.
 func ParseDate(line string, opts DateParseOptions) (time.Time, bool) {
-	if matches := dateWithYearPattern.FindStringSubmatch(line); len(matches) >= 5 {
+	t, yearless, ok := parseDate(line, opts)
+	if !ok || !yearless {
+		return t, ok
+	}
+	return anchorYear(t, opts), true
+}
+
+func anchorYear(wall time.Time, opts DateParseOptions) time.Time {
+	t := withYear(wall, inferYear(opts), opts.location())
+	now := opts.ReferenceTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if t.After(now) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// parseDate is ParseDate without year inference: a year-less timestamp comes
+// back as a UTC wall clock in wallClockYear with yearless set.
+func parseDate(line string, opts DateParseOptions) (time.Time, bool, bool) {
+	loc := opts.location()
+
+	// Header formats only accept a timestamp where the header puts it, so a
+	// date quoted in the message cannot win.
+	anchored := false
+	switch opts.Format {
+	case DateFormatSyslog:
+		anchored = true
+	case DateFormatJava:
+		header := javaHeaderPattern.FindStringIndex(line)
+		if header == nil {
+			return time.Time{}, false, false
+		}
+		line, anchored = line[header[1]:], true
+	}
+
+	if matches := findDate(dateWithYearPattern, line, anchored); len(matches) >= 5 {
 		dateStr := matches[1] + " " + matches[2] + " " + matches[3] + " " + matches[4]
-		if t, err := time.Parse(layoutWithYear, dateStr); err == nil {
-			return t, true
+		if t, err := time.ParseInLocation(layoutWithYear, dateStr, loc); err == nil {
+			return t, false, true
 		}
 	}
 
-	if matches := isoDatePattern.FindStringSubmatch(line); len(matches) >= 5 {
+	if matches := findDate(isoDatePattern, line, anchored); len(matches) >= 5 {
 		dateStr := matches[1] + "-" + matches[2] + "-" + matches[3] + " " + matches[4]
-		if t, err := time.Parse(layoutISO, dateStr); err == nil {
-			return t, true
+		if t, err := time.ParseInLocation(layoutISO, dateStr, loc); err == nil {
+			return t, false, true
 		}
 	}
 
-	if matches := rfc3339Pattern.FindStringSubmatch(line); len(matches) >= 5 {
+	if matches := findDate(rfc3339Pattern, line, anchored); len(matches) >= 5 {
 		dateStr := matches[1] + "-" + matches[2] + "-" + matches[3] + "T" + matches[4]
-		if t, err := time.Parse(layoutRFC3339, dateStr); err == nil {
-			return t, true
+		if t, err := time.ParseInLocation(layoutRFC3339, dateStr, offsetLocation(matches[5], loc)); err == nil {
+			return t, false, true
 		}
 	}
 
-	if matches := dateWithoutYearPattern.FindStringSubmatch(line); len(matches) >= 4 {
-		year := inferYear(opts)
+	if matches := findDate(dateWithoutYearPattern, line, anchored); len(matches) >= 4 {
 		dateStr := matches[1] + " " + matches[2] + " " + matches[3]
 
 		if t, err := time.Parse(layoutWithoutYear, dateStr); err == nil {
-			t = time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
-			now := opts.ReferenceTime
-			if now.IsZero() {
-				now = time.Now()
-			}
-			if t.After(now) {
-				t = t.AddDate(-1, 0, 0)
-			}
-			return t, true
+			return time.Date(wallClockYear, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), true, true
 		}
 	}
 
-	return time.Time{}, false
+	return time.Time{}, false, false
+}
+
+// findDate returns the submatches of pattern, or nil when anchored is set
+// and the match does not start the line.
+func findDate(pattern *regexp.Regexp, line string, anchored bool) []string {
+	idx := pattern.FindStringSubmatchIndex(line)
+	if idx == nil || (anchored && idx[0] != 0) {
+		return nil
+	}
+	matches := make([]string, len(idx)/2)
+	for i := range matches {
+		if idx[2*i] >= 0 {
+			matches[i] = line[idx[2*i]:idx[2*i+1]]
+		}
+	}
+	return matches
+}
+
+// wallClockYear is a leap year so that Feb 29 survives until the real year is known.
+const wallClockYear = 2000
+
+func withYear(wall time.Time, year int, loc *time.Location) time.Time {
+	return time.Date(year, wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// offsetLocation turns an RFC3339 suffix ("Z", "+02:00", "-0700") into a
+// fixed zone, falling back to loc when there is none.
+func offsetLocation(offset string, loc *time.Location) *time.Location {
+	switch offset {
+	case "":
+		return loc
+	case "Z":
+		return time.UTC
+	}
+	digits := strings.ReplaceAll(offset[1:], ":", "")
+	if len(digits) != 4 {
+		return loc
+	}
+	hours, errH := strconv.Atoi(digits[:2])
+	minutes, errM := strconv.Atoi(digits[2:])
+	if errH != nil || errM != nil {
+		return loc
+	}
+	seconds := hours*3600 + minutes*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return time.FixedZone(offset, seconds)
 }
 
 func inferYear(opts DateParseOptions) int {
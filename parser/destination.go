@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Destination is a virtual server listener: address, port and route domain.
+type Destination struct {
+	Address     netip.Addr // Unspecified for wildcard listeners
+	Port        int        // 0 means any port
+	RouteDomain int
+	Name        string // Virtual address name when the destination is not a literal IP
+}
+
+// Well-known service names tmsh writes in place of port numbers.
+var servicePorts = map[string]int{
+	"any": 0, "ftp": 21, "ssh": 22, "telnet": 23, "smtp": 25, "domain": 53, "http": 80,
+	"pop3": 110, "imap": 143, "snmp": 161, "ldap": 389, "https": 443, "smtps": 465,
+	"ldaps": 636, "imaps": 993, "pop3s": 995, "radius": 1812, "mysql": 3306, "rdp": 3389,
+	"sip": 5060, "http-alt": 8080,
+}
+
+// ParseDestination splits a cleaned virtual server destination such as
+// 10.1.1.1%2:443, 2001:db8::1.443 or 0.0.0.0:any. IPv6 destinations separate
+// the port with a dot, and so do named IPv6 virtual addresses such as
+// web_v6.443, which have no colon at all.
+func ParseDestination(dest string) (Destination, error) {
+	var d Destination
+
+	if addr, err := netip.ParseAddr(dest); err == nil && addr.Zone() == "" {
+		return d, fmt.Errorf("bigip: destination %q has no port", dest)
+	}
+	sep := ":"
+	if strings.Count(dest, ":") != 1 {
+		sep = "."
+	}
+	idx := strings.LastIndex(dest, sep)
+	if idx == -1 {
+		return d, fmt.Errorf("bigip: destination %q has no port", dest)
+	}
+	host, port := dest[:idx], dest[idx+1:]
+
+	if p, ok := servicePorts[port]; ok {
+		d.Port = p
+	} else {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 0 || p > 65535 {
+			return d, fmt.Errorf("bigip: destination %q has invalid port %q", dest, port)
+		}
+		d.Port = p
+	}
+
+	if i := strings.Index(host, "%"); i != -1 {
+		rd, err := strconv.Atoi(host[i+1:])
+		if err != nil {
+			return d, fmt.Errorf("bigip: destination %q has invalid route domain", dest)
+		}
+		d.RouteDomain = rd
+		host = host[:i]
+	}
+
+	switch host {
+	case "any", "0.0.0.0":
+		d.Address = netip.IPv4Unspecified()
+		return d, nil
+	case "any6", "::":
+		d.Address = netip.IPv6Unspecified()
+		return d, nil
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		d.Name = host
+		return d, nil
+	}
+	d.Address = addr
+	return d, nil
+}
+
+func (d Destination) Wildcard() bool {
+	return d.Address.IsValid() && d.Address.IsUnspecified()
+}
+
+func (d Destination) String() string {
+	host := d.Name
+	if d.Address.IsValid() {
+		host = d.Address.String()
+	}
+	if d.RouteDomain != 0 {
+		host += "%" + strconv.Itoa(d.RouteDomain)
+	}
+	port := "any"
+	if d.Port != 0 {
+		port = strconv.Itoa(d.Port)
+	}
+	if d.Address.Is6() {
+		return host + "." + port
+	}
+	return host + ":" + port
+}
+
+// ParsePrefix parses a self IP or mask-qualified address such as
+// 10.1.1.5%2/24 into its network prefix and route domain.
+func ParsePrefix(address string) (netip.Prefix, int, error) {
+	rd := 0
+	if i := strings.Index(address, "%"); i != -1 {
+		j := strings.Index(address[i:], "/")
+		rdText := address[i+1:]
+		rest := ""
+		if j != -1 {
+			rdText = address[i+1 : i+j]
+			rest = address[i+j:]
+		}
+		var err error
+		if rd, err = strconv.Atoi(rdText); err != nil {
+			return netip.Prefix{}, 0, fmt.Errorf("bigip: invalid route domain in %q", address)
+		}
+		address = address[:i] + rest
+	}
+	if !strings.Contains(address, "/") {
+		addr, err := netip.ParseAddr(address)
+		if err != nil {
+			return netip.Prefix{}, 0, fmt.Errorf("bigip: invalid address %q: %w", address, err)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), rd, nil
+	}
+	prefix, err := netip.ParsePrefix(address)
+	if err != nil {
+		return netip.Prefix{}, 0, fmt.Errorf("bigip: invalid address %q: %w", address, err)
+	}
+	return prefix.Masked(), rd, nil
+}
+
+// MaskBits converts a dotted or IPv6 netmask to a prefix length. An empty
+// mask means a host route.
+func MaskBits(mask string, addr netip.Addr) int {
+	switch mask {
+	case "":
+		return addr.BitLen()
+	case "any", "any6":
+		return 0
+	}
+	m, err := netip.ParseAddr(mask)
+	if err != nil {
+		return addr.BitLen()
+	}
+	bits := 0
+	for _, b := range m.AsSlice() {
+		for i := 7; i >= 0; i-- {
+			if b&(1<<i) == 0 {
+				return bits
+			}
+			bits++
+		}
+	}
+	return bits
+}
+
+// VirtualDestination parses the destination of a virtual server, resolving
+// named virtual addresses in the folder of the destination to their IP.
+func (c *BigIPConfig) VirtualDestination(vs *VirtualServerConfig) (Destination, error) {
+	d, err := ParseDestination(vs.Destination)
+	if err != nil || d.Address.IsValid() {
+		return d, err
+	}
+	va, ok := c.VirtualAddressOf(vs, d)
+	if !ok {
+		return d, nil
+	}
+	prefix, rd, err := ParsePrefix(va.Address)
+	if err != nil {
+		return d, nil
+	}
+	d.Address = prefix.Addr()
+	if d.RouteDomain == 0 {
+		d.RouteDomain = rd
+	}
+	return d, nil
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		dest    string
+		address string // Empty for a named virtual address
+		port    int
+		rd      int
+		name    string
+		str     string
+	}{
+		{"10.1.1.1:443", "10.1.1.1", 443, 0, "", "10.1.1.1:443"},
+		{"10.1.1.1%2:https", "10.1.1.1", 443, 2, "", "10.1.1.1%2:443"},
+		{"0.0.0.0:any", "0.0.0.0", 0, 0, "", "0.0.0.0:any"},
+		{"any%3:80", "0.0.0.0", 80, 3, "", "0.0.0.0%3:80"},
+		{"2001:db8::1.443", "2001:db8::1", 443, 0, "", "2001:db8::1.443"},
+		{"2001:db8::1%10.8080", "2001:db8::1", 8080, 10, "", "2001:db8::1%10.8080"},
+		{"any6.any", "::", 0, 0, "", "::.any"},
+		{"::%1.53", "::", 53, 1, "", "::%1.53"},
+		{"app_vip%4:443", "", 443, 4, "app_vip", "app_vip%4:443"},
+		{"web_v6.443", "", 443, 0, "web_v6", "web_v6:443"},
+		{"web_v6%2.https", "", 443, 2, "web_v6", "web_v6%2:443"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDestination(tt.dest)
+		if err != nil {
+			t.Errorf("ParseDestination(%q): %v", tt.dest, err)
+			continue
+		}
+		var want netip.Addr
+		if tt.address != "" {
+			want = netip.MustParseAddr(tt.address)
+		}
+		if d.Address != want || d.Port != tt.port || d.RouteDomain != tt.rd || d.Name != tt.name {
+			t.Errorf("ParseDestination(%q) = %+v, want %s port %d rd %d name %q", tt.dest, d, tt.address, tt.port, tt.rd, tt.name)
+		}
+		if got := d.String(); got != tt.str {
+			t.Errorf("ParseDestination(%q).String() = %q, want %q", tt.dest, got, tt.str)
+		}
+	}
+
+	for _, dest := range []string{"10.1.1.1", "2001:db8::1", "web_v6", "10.1.1.1:70000", "10.1.1.1:bogus", "10.1.1.1%x:80", "2001:db8::1.-1"} {
+		if d, err := ParseDestination(dest); err == nil {
+			t.Errorf("ParseDestination(%q) = %+v, want an error", dest, d)
+		}
+	}
+}
+
+func TestVirtualDestination(t *testing.T) {
+	c := &BigIPConfig{VirtualAddresses: map[string]*VirtualAddress{
+		"/Common/web_v4":    {Name: "web_v4", Partition: "Common", Path: "/Common/web_v4", Address: "10.1.1.10"},
+		"/Common/web_v6":    {Name: "web_v6", Partition: "Common", Path: "/Common/web_v6", Address: "2001:db8::10"},
+		"/Tenant/web_v4":    {Name: "web_v4", Partition: "Tenant", Path: "/Tenant/web_v4", Address: "10.2.2.20%2"},
+		"/Tenant/app/vip":   {Name: "vip", Partition: "Tenant", Path: "/Tenant/app/vip", Address: "10.3.3.30"},
+		"/Common/10.4.4.40": {Name: "10.4.4.40", Partition: "Common", Path: "/Common/10.4.4.40", Address: "10.4.4.40"},
+	}}
+	tests := []struct {
+		destination string // As written in bigip.conf
+		want        string
+		va          string // Path of the virtual address found, if any
+	}{
+		{"/Common/web_v4:443", "10.1.1.10:443", "/Common/web_v4"},
+		{"/Common/web_v6.443", "2001:db8::10.443", "/Common/web_v6"},
+		{"/Common/web_v6%2.80", "2001:db8::10%2.80", "/Common/web_v6"},
+		{"/Tenant/web_v4:443", "10.2.2.20%2:443", "/Tenant/web_v4"},
+		{"/Tenant/app/vip:80", "10.3.3.30:80", "/Tenant/app/vip"},
+		{"/Common/10.4.4.40:80", "10.4.4.40:80", "/Common/10.4.4.40"},
+		{"/Tenant/10.4.4.40:80", "10.4.4.40:80", ""},
+		{"/Common/missing.443", "missing:443", ""},
+	}
+	for _, tt := range tests {
+		vs := &VirtualServerConfig{Destination: CleanName(tt.destination), DestinationPath: tt.destination}
+		d, err := c.VirtualDestination(vs)
+		if err != nil {
+			t.Errorf("VirtualDestination(%q): %v", tt.destination, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("VirtualDestination(%q) = %s, want %s", tt.destination, got, tt.want)
+		}
+		got := ""
+		if va, ok := c.VirtualAddressOf(vs, d); ok {
+			got = va.Path
+		}
+		if got != tt.va {
+			t.Errorf("VirtualAddressOf(%q) = %q, want %q", tt.destination, got, tt.va)
+		}
+	}
+}
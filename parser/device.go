@@ -0,0 +1,277 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DeviceInfo struct {
+	Hostname      string
+	Product       string // BIG-IP, BIG-IQ, ...
+	Version       string // 15.1.8
+	Build         string // 0.0.7
+	Edition       string // Final, Point Release 2, Hotfix HF1, ...
+	Hotfix        string // Set when the edition is a hotfix
+	PlatformID    string // Z100, C119, ...
+	MarketingName string // BIG-IP Virtual Edition, BIG-IP i5800, ...
+	Serial        string // Chassis ID
+	ManagementIP  string
+	TimeZone      string         // sys ntp timezone or cm device time-zone
+	Location      *time.Location // Resolved TimeZone, or etc/localtime; nil when unknown
+	Modules       []ProvisionedModule
+	HARole        string // standalone, active, standby, forced-offline, offline
+	FailoverState string // As reported by cm device or the prompt
+	SyncStatus    string // In Sync, Changes Pending, ...
+	Uptime        time.Duration
+	GeneratedAt   time.Time // Newest file in the qkview archive
+}
+
+type ProvisionedModule struct {
+	Name  string
+	Level string // nominal, minimum, dedicated
+}
+
+var (
+	versionFieldPattern  = regexp.MustCompile(`^(\w+):\s*(.+?)\s*$`)
+	platformFieldPattern = regexp.MustCompile(`^(\w+)=(.+?)\s*$`)
+	deviceBlockPattern   = regexp.MustCompile(`^(sys global-settings|sys ntp|cm device\s+\S+|sys provision\s+\S+)\s*\{(.*)$`)
+	deviceAttrPattern    = regexp.MustCompile(`^\s*([\w-]+)\s+(.+?)\s*$`)
+)
+
+// ParseDeviceInfo collects the identity of the device a qkview was taken on
+// from VERSION, PLATFORM, config/bigip_base.conf, var/prompt/ps1,
+// etc/localtime and proc/uptime. Missing files leave the matching fields empty.
+func ParseDeviceInfo(extractDir string) (*DeviceInfo, error) {
+	d := &DeviceInfo{}
+
+	if err := d.parseVersion(filepath.Join(extractDir, "VERSION")); err != nil {
+		return d, err
+	}
+	if err := d.parsePlatform(filepath.Join(extractDir, "PLATFORM")); err != nil {
+		return d, err
+	}
+	if err := d.parseBaseConfig(filepath.Join(extractDir, "config", "bigip_base.conf")); err != nil {
+		return d, err
+	}
+	d.parsePrompt(filepath.Join(extractDir, "var", "prompt", "ps1"))
+	d.parseUptime(filepath.Join(extractDir, "proc", "uptime"))
+
+	if strings.Contains(strings.ToLower(d.Edition), "hotfix") {
+		d.Hotfix = d.Edition
+	}
+	d.Location = deviceLocation(extractDir, d.TimeZone)
+	if d.TimeZone == "" && d.Location != nil {
+		d.TimeZone = d.Location.String()
+	}
+	return d, nil
+}
+
+func (d *DeviceInfo) parseVersion(path string) error {
+	fields, err := readFields(path, versionFieldPattern)
+	if err != nil || fields == nil {
+		return err
+	}
+
+	d.Product = fields["Product"]
+	d.Version = fields["Version"]
+	d.Build = fields["Build"]
+	d.Edition = fields["Edition"]
+	return nil
+}
+
+// PLATFORM holds key=value lines such as "platform=Z100".
+func (d *DeviceInfo) parsePlatform(path string) error {
+	fields, err := readFields(path, platformFieldPattern)
+	if err != nil || fields == nil {
+		return err
+	}
+
+	if d.PlatformID == "" {
+		d.PlatformID = fields["platform"]
+	}
+	return nil
+}
+
+func (d *DeviceInfo) parseBaseConfig(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("device: failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	type block struct {
+		header string
+		attrs  map[string]string
+	}
+	var blocks []block
+	var current *block
+	depth := 0
+
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if depth == 0 {
+			if matches := deviceBlockPattern.FindStringSubmatch(trimmed); matches != nil {
+				blocks = append(blocks, block{header: matches[1], attrs: make(map[string]string)})
+				current = &blocks[len(blocks)-1]
+				// One-line blocks such as "sys provision ltm { level nominal }"
+				if inline := strings.TrimSpace(strings.TrimSuffix(matches[2], "}")); inline != "" {
+					if m := deviceAttrPattern.FindStringSubmatch(inline); m != nil {
+						current.attrs[m[1]] = m[2]
+					}
+				}
+			} else {
+				current = nil
+			}
+		} else if current != nil && depth == 1 {
+			if m := deviceAttrPattern.FindStringSubmatch(trimmed); m != nil && !strings.HasSuffix(m[2], "{") {
+				current.attrs[m[1]] = strings.Trim(m[2], `"`)
+			}
+		}
+
+		depth += strings.Count(trimmed, "{") - strings.Count(trimmed, "}")
+		if depth <= 0 {
+			depth = 0
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("device: error reading %s: %w", path, err)
+	}
+
+	var devices []map[string]string
+	for _, b := range blocks {
+		switch {
+		case b.header == "sys global-settings":
+			d.Hostname = b.attrs["hostname"]
+		case b.header == "sys ntp":
+			d.TimeZone = b.attrs["timezone"]
+		case strings.HasPrefix(b.header, "sys provision "):
+			level := b.attrs["level"]
+			if level != "" && level != "none" {
+				name := strings.TrimSpace(strings.TrimPrefix(b.header, "sys provision "))
+				d.Modules = append(d.Modules, ProvisionedModule{Name: name, Level: level})
+			}
+		case strings.HasPrefix(b.header, "cm device "):
+			devices = append(devices, b.attrs)
+		}
+	}
+	sort.Slice(d.Modules, func(i, j int) bool { return d.Modules[i].Name < d.Modules[j].Name })
+
+	// The local device is flagged self-device; older versions only match by hostname.
+	var self map[string]string
+	for _, attrs := range devices {
+		if attrs["self-device"] == "true" || (self == nil && attrs["hostname"] == d.Hostname) {
+			self = attrs
+		}
+	}
+	if self != nil {
+		if d.Hostname == "" {
+			d.Hostname = self["hostname"]
+		}
+		setIfEmpty(&d.Version, self["version"])
+		setIfEmpty(&d.Build, self["build"])
+		setIfEmpty(&d.Edition, self["edition"])
+		setIfEmpty(&d.Product, self["product"])
+		d.PlatformID = firstNonEmpty(self["platform-id"], d.PlatformID)
+		d.MarketingName = self["marketing-name"]
+		d.Serial = self["chassis-id"]
+		d.ManagementIP = self["management-ip"]
+		setIfEmpty(&d.TimeZone, self["time-zone"])
+		d.FailoverState = self["failover-state"]
+	}
+	if len(devices) == 1 {
+		d.HARole = "standalone"
+	} else if d.FailoverState != "" {
+		d.HARole = d.FailoverState
+	}
+	return nil
+}
+
+// The shell prompt status reads e.g. "Active:In Sync" or "Standby:Changes Pending".
+func (d *DeviceInfo) parsePrompt(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	status := strings.TrimSpace(string(data))
+	if status == "" {
+		return
+	}
+
+	state, sync, _ := strings.Cut(status, ":")
+	state = strings.ToLower(strings.TrimSpace(state))
+	switch state {
+	case "active", "standby", "forcedoffline", "offline":
+		if state == "forcedoffline" {
+			state = "forced-offline"
+		}
+		if d.HARole != "standalone" {
+			d.HARole = state
+		}
+		setIfEmpty(&d.FailoverState, state)
+	}
+	d.SyncStatus = strings.TrimSpace(sync)
+}
+
+func (d *DeviceInfo) parseUptime(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return
+	}
+	if seconds, err := strconv.ParseFloat(fields[0], 64); err == nil {
+		d.Uptime = time.Duration(seconds) * time.Second
+	}
+}
+
+func readFields(path string, pattern *regexp.Regexp) (map[string]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("device: failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if matches := pattern.FindStringSubmatch(strings.TrimSpace(scanner.Text())); matches != nil {
+			if _, ok := fields[matches[1]]; !ok {
+				fields[matches[1]] = matches[2]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("device: error reading %s: %w", path, err)
+	}
+	return fields, nil
+}
+
+func setIfEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
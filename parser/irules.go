@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+type RuleReference struct {
+	Kind string // pool, data-group
+	Name string // Cleaned object name
+	Line int    // 1-based line within the rule body
+
+	written string // Name as the rule writes it, with or without a path
+}
+
+// ReferencePath returns the full path of an object the rule references; see
+// ResolvePath for names written without one.
+func (r *RuleConfig) ReferencePath(ref RuleReference, defined func(path string) bool) string {
+	return ResolvePath(ref.written, r.Partition, defined)
+}
+
+var (
+	ruleClassPattern = regexp.MustCompile(`\bclass\s+(match|search|lookup|exists|element|names|get|size|type|startsearch)\b`)
+	rulePoolPattern  = regexp.MustCompile(`(?:^|[\[{};])\s*(?:pool|active_members)\s+(?:-list\s+)?([^\s\]\[;{}$]+)`)
+)
+
+// References returns the pools and data groups a rule names literally.
+// Names built from variables or command substitutions are skipped.
+func (r *RuleConfig) References() []RuleReference {
+	var refs []RuleReference
+
+	for i, raw := range strings.Split(r.Body, "\n") {
+		code := strings.TrimSpace(raw)
+		if code == "" || strings.HasPrefix(code, "#") {
+			continue
+		}
+
+		for _, loc := range ruleClassPattern.FindAllStringSubmatchIndex(code, -1) {
+			ref := classArgument(code[loc[2]:loc[3]], strings.Fields(commandArgs(code[loc[1]:])))
+			if ref == "" || strings.HasPrefix(ref, "$") || strings.HasPrefix(ref, "[") {
+				continue
+			}
+			refs = append(refs, RuleReference{Kind: "data-group", Name: CleanName(ref), Line: i + 1, written: ref})
+		}
+
+		for _, m := range rulePoolPattern.FindAllStringSubmatch(code, -1) {
+			written := strings.Trim(m[1], `"`)
+			if name := CleanName(written); name != "" {
+				refs = append(refs, RuleReference{Kind: "pool", Name: name, Line: i + 1, written: written})
+			}
+		}
+	}
+
+	return refs
+}
+
+// classArgument picks the data group from the arguments of a class
+// subcommand: match, lookup and element take it last, the others
+// (class search <dg> starts_with "/api", class get <dg> <pattern>, ...)
+// first after their options.
+func classArgument(subcommand string, args []string) string {
+	var operands []string
+	for i, arg := range args {
+		if arg == "--" {
+			operands = append(operands, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			operands = append(operands, arg)
+		}
+	}
+	if len(operands) == 0 {
+		return ""
+	}
+
+	arg := operands[0]
+	switch subcommand {
+	case "match", "lookup", "element":
+		arg = operands[len(operands)-1]
+	}
+	return strings.Trim(arg, `"`)
+}
+
+// commandArgs returns the arguments of a TCL command up to the end of its
+// enclosing [...] or statement, keeping nested command substitutions intact.
+func commandArgs(rest string) string {
+	depth := 0
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				return rest[:i]
+			}
+			depth--
+		case ';', '{', '}':
+			if depth == 0 {
+				return rest[:i]
+			}
+		}
+	}
+	return rest
+}
+
+// CountTCLBraces returns the net brace depth change of a TCL line, ignoring
+// backslash-escaped braces.
+func CountTCLBraces(line string) int {
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+	}
+	return depth
+}
@@ -0,0 +1,32 @@
+package parser
+
+import "testing"
+
+func TestRuleDataGroupReferences(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{`if { [class match [IP::client_addr] equals allowed_hosts] } {`, "allowed_hosts"},
+		{`set v [class lookup [HTTP::host] /Common/host_map]`, "host_map"},
+		{`set e [class element -name 0 redirects]`, "redirects"},
+		{`set id [class search -index api_paths starts_with "/api"]`, "api_paths"},
+		{`set hits [class search -- api_paths starts_with [HTTP::path]]`, "api_paths"},
+		{`foreach n [class names -nocase blocked "*.example.com"] {`, "blocked"},
+		{`set pairs [class get countries "D*"]`, "countries"},
+		{`log local0. [class size countries]`, "countries"},
+		{`if { [class exists $dg] } {`, ""},
+	}
+	for _, tt := range tests {
+		rule := &RuleConfig{Body: tt.code}
+		var got string
+		for _, ref := range rule.References() {
+			if ref.Kind == "data-group" {
+				got = ref.Name
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: data group %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+
+	"goqkview/interfaces"
+)
+
+// systemd journal file layout, see systemd's journal-def.h and
+// https://systemd.io/JOURNAL_FILE_FORMAT/.
+const (
+	journalSignature = "LPKSHHRH"
+
+	journalHeaderMinSize = 208
+
+	// Header incompatible flags
+	journalCompressedXZ   = 1 << 0
+	journalCompressedLZ4  = 1 << 1
+	journalKeyedHash      = 1 << 2
+	journalCompressedZSTD = 1 << 3
+	journalCompact        = 1 << 4
+	journalKnownFlags     = journalCompressedXZ | journalCompressedLZ4 | journalKeyedHash | journalCompressedZSTD | journalCompact
+
+	// Object types and flags
+	journalObjectData  = 1
+	journalObjectEntry = 3
+
+	journalObjectXZ   = 1 << 0
+	journalObjectLZ4  = 1 << 1
+	journalObjectZSTD = 1 << 2
+
+	journalObjectHeaderSize   = 16
+	journalEntryItemsOffset   = 64 // seqnum, realtime, monotonic, boot_id, xor_hash
+	journalDataPayload        = 64 // hash, next_hash, next_field, entry, entry_array, n_entries
+	journalDataPayloadCompact = 72 // ... plus tail_entry_array_offset and _n_entries
+
+	journalMaxObjectSize = 64 << 20
+)
+
+// JournalEntry is one record of a journal file.
+type JournalEntry struct {
+	Realtime time.Time
+	Fields   map[string]string
+}
+
+// Journal reads a systemd journal file without journalctl.
+type Journal struct {
+	file    *os.File
+	compact bool
+	start   uint64 // First object, right after the header
+	tail    uint64 // Last object written
+	end     uint64 // End of the arena
+	data    map[uint64][2]string
+	zstd    *zstd.Decoder
+}
+
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("journal: failed to open %s: %w", path, err)
+	}
+
+	header := make([]byte, journalHeaderMinSize)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("journal: %s: short header: %w", path, err)
+	}
+	if string(header[:8]) != journalSignature {
+		file.Close()
+		return nil, fmt.Errorf("journal: %s: not a journal file", path)
+	}
+	flags := binary.LittleEndian.Uint32(header[12:])
+	if flags&^journalKnownFlags != 0 {
+		file.Close()
+		return nil, fmt.Errorf("journal: %s: unsupported incompatible flags %#x", path, flags)
+	}
+
+	headerSize := binary.LittleEndian.Uint64(header[88:])
+	arenaSize := binary.LittleEndian.Uint64(header[96:])
+	return &Journal{
+		file:    file,
+		compact: flags&journalCompact != 0,
+		start:   headerSize,
+		tail:    binary.LittleEndian.Uint64(header[136:]),
+		end:     headerSize + arenaSize,
+		data:    make(map[uint64][2]string),
+	}, nil
+}
+
+func (j *Journal) Close() error {
+	if j.zstd != nil {
+		j.zstd.Close()
+	}
+	return j.file.Close()
+}
+
+// Entries calls fn for every entry in file order. A damaged object ends the
+// walk with an error; entries already delivered stay valid.
+func (j *Journal) Entries(fn func(JournalEntry) error) error {
+	header := make([]byte, journalObjectHeaderSize)
+	for offset := j.start; offset != 0 && offset <= j.tail && offset+journalObjectHeaderSize <= j.end; {
+		if _, err := j.file.ReadAt(header, int64(offset)); err != nil {
+			return fmt.Errorf("journal: object at %d: %w", offset, err)
+		}
+		kind := header[0]
+		size := binary.LittleEndian.Uint64(header[8:])
+		if size < journalObjectHeaderSize || size > journalMaxObjectSize {
+			return fmt.Errorf("journal: object at %d has invalid size %d", offset, size)
+		}
+
+		if kind == journalObjectEntry {
+			entry, err := j.readEntry(offset, size)
+			if err != nil {
+				return err
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		offset += (size + 7) &^ 7
+	}
+	return nil
+}
+
+func (j *Journal) readEntry(offset, size uint64) (JournalEntry, error) {
+	if size < journalEntryItemsOffset {
+		return JournalEntry{}, fmt.Errorf("journal: entry at %d is truncated", offset)
+	}
+	buf := make([]byte, size)
+	if _, err := j.file.ReadAt(buf, int64(offset)); err != nil {
+		return JournalEntry{}, fmt.Errorf("journal: entry at %d: %w", offset, err)
+	}
+
+	entry := JournalEntry{
+		Realtime: time.UnixMicro(int64(binary.LittleEndian.Uint64(buf[24:]))).UTC(),
+		Fields:   make(map[string]string),
+	}
+
+	itemSize := uint64(16) // object offset and hash
+	if j.compact {
+		itemSize = 4 // 32-bit object offset only
+	}
+	for pos := uint64(journalEntryItemsOffset); pos+itemSize <= size; pos += itemSize {
+		var dataOffset uint64
+		if j.compact {
+			dataOffset = uint64(binary.LittleEndian.Uint32(buf[pos:]))
+		} else {
+			dataOffset = binary.LittleEndian.Uint64(buf[pos:])
+		}
+		field, err := j.readData(dataOffset)
+		if err != nil {
+			return entry, err
+		}
+		if field[0] != "" {
+			entry.Fields[field[0]] = field[1]
+		}
+	}
+	return entry, nil
+}
+
+// readData returns the KEY=value pair stored in a data object. Short fields
+// such as _HOSTNAME repeat across entries and are cached.
+func (j *Journal) readData(offset uint64) ([2]string, error) {
+	if field, ok := j.data[offset]; ok {
+		return field, nil
+	}
+
+	header := make([]byte, journalObjectHeaderSize)
+	if _, err := j.file.ReadAt(header, int64(offset)); err != nil {
+		return [2]string{}, fmt.Errorf("journal: data at %d: %w", offset, err)
+	}
+	size := binary.LittleEndian.Uint64(header[8:])
+	payloadAt := uint64(journalDataPayload)
+	if j.compact {
+		payloadAt = journalDataPayloadCompact
+	}
+	if header[0] != journalObjectData || size < payloadAt || size > journalMaxObjectSize {
+		return [2]string{}, fmt.Errorf("journal: invalid data object at %d", offset)
+	}
+
+	payload := make([]byte, size-payloadAt)
+	if _, err := j.file.ReadAt(payload, int64(offset+payloadAt)); err != nil {
+		return [2]string{}, fmt.Errorf("journal: data at %d: %w", offset, err)
+	}
+	payload, err := j.decompress(header[1], payload)
+	if err != nil {
+		return [2]string{}, fmt.Errorf("journal: data at %d: %w", offset, err)
+	}
+
+	key, value, ok := bytes.Cut(payload, []byte("="))
+	if !ok {
+		return [2]string{}, nil
+	}
+	field := [2]string{string(key), string(value)}
+	if len(payload) <= 128 {
+		j.data[offset] = field
+	}
+	return field, nil
+}
+
+func (j *Journal) decompress(flags byte, payload []byte) ([]byte, error) {
+	switch {
+	case flags&journalObjectZSTD != 0:
+		if j.zstd == nil {
+			decoder, err := zstd.NewReader(nil)
+			if err != nil {
+				return nil, err
+			}
+			j.zstd = decoder
+		}
+		return j.zstd.DecodeAll(payload, nil)
+	case flags&journalObjectLZ4 != 0:
+		// 64-bit uncompressed size followed by a raw LZ4 block
+		if len(payload) < 8 {
+			return nil, errors.New("truncated lz4 payload")
+		}
+		size := binary.LittleEndian.Uint64(payload)
+		if size > journalMaxObjectSize {
+			return nil, fmt.Errorf("lz4 payload too large: %d", size)
+		}
+		out := make([]byte, size)
+		n, err := lz4.UncompressBlock(payload[8:], out)
+		if err != nil {
+			return nil, err
+		}
+		return out[:n], nil
+	case flags&journalObjectXZ != 0:
+		r, err := xz.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(io.LimitReader(r, journalMaxObjectSize))
+	}
+	return payload, nil
+}
+
+// syslogKeywords is indexed by the journal PRIORITY.
+var syslogKeywords = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var messageIDPrefixPattern = regexp.MustCompile(`^([0-9a-fA-F]{8}):\d:\s*`)
+
+// parseJournalFile turns the entries of a journal file into log entries.
+// Entries without a PRIORITY are skipped, as are structured lines without a level.
+func (p *Parser) parseJournalFile(ctx context.Context, path, source string, dateOpts DateParseOptions, profile LogProfile) ([]interfaces.LogEntry, []error) {
+	journal, err := OpenJournal(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer journal.Close()
+
+	facility := profile.Facility
+	if facility == "" {
+		facility = "journal"
+	}
+	loc := dateOpts.location()
+
+	var entries []interfaces.LogEntry
+	err = journal.Entries(func(je JournalEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		priority, err := strconv.Atoi(je.Fields["PRIORITY"])
+		if err != nil || priority < 0 || priority >= len(syslogKeywords) {
+			return nil
+		}
+
+		entry := interfaces.LogEntry{
+			Path:           path,
+			Status:         messageIDStatuses[priority],
+			SeverityMethod: SeverityJournal,
+			Source:         source,
+			Facility:       facility,
+			Host:           je.Fields["_HOSTNAME"],
+			Severity:       syslogKeywords[priority],
+			Process:        firstNonEmpty(je.Fields["SYSLOG_IDENTIFIER"], je.Fields["_COMM"]),
+			Unit:           je.Fields["_SYSTEMD_UNIT"],
+			Message:        je.Fields["MESSAGE"],
+		}
+		entry.PID, _ = strconv.Atoi(firstNonEmpty(je.Fields["SYSLOG_PID"], je.Fields["_PID"]))
+
+		timestamp := je.Realtime
+		if usec, err := strconv.ParseInt(je.Fields["_SOURCE_REALTIME_TIMESTAMP"], 10, 64); err == nil && usec > 0 {
+			timestamp = time.UnixMicro(usec)
+		}
+		local := timestamp.In(loc)
+		entry.Timestamp = timestamp.UTC()
+		entry.UTCOffset = local.Format("-07:00")
+
+		if m := messageIDPrefixPattern.FindStringSubmatch(entry.Message); m != nil {
+			entry.MessageID = strings.ToUpper(m[1])
+			entry.Message = entry.Message[len(m[0]):]
+		}
+		if first, rest, ok := strings.Cut(entry.Message, "\n"); ok {
+			entry.Message, entry.Body = first, rest
+		}
+
+		// Render the entry like journalctl's short output
+		process := entry.Process
+		if entry.PID != 0 {
+			process += "[" + strconv.Itoa(entry.PID) + "]"
+		}
+		entry.Line = fmt.Sprintf("%s %s %s: %s", local.Format("Jan 02 15:04:05"), entry.Host, process,
+			strings.SplitN(je.Fields["MESSAGE"], "\n", 2)[0])
+
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return entries, []error{fmt.Errorf("journal %s: %w", path, err)}
+	}
+	return entries, nil
+}
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// The journal-*.journal files in testdata are written by
+// testdata/gen_journal.go for the edge cases. journald-*.journal.xz were
+// written by systemd-journald itself, so they check the reader against the
+// real format rather than against our own writer.
+
+func readJournal(t *testing.T, path string) ([]JournalEntry, error) {
+	t.Helper()
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal(%s): %v", path, err)
+	}
+	defer j.Close()
+
+	var entries []JournalEntry
+	err = j.Entries(func(e JournalEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+func TestJournalEntries(t *testing.T) {
+	for _, name := range []string{"journal-regular.journal", "journal-compact.journal"} {
+		t.Run(name, func(t *testing.T) {
+			entries, err := readJournal(t, filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatalf("Entries: %v", err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+
+			first, second := entries[0], entries[1]
+			if want := time.Date(2025, 10, 9, 8, 53, 20, 0, time.UTC); !first.Realtime.Equal(want) {
+				t.Errorf("first realtime = %v, want %v", first.Realtime, want)
+			}
+			for key, want := range map[string]string{
+				"_HOSTNAME":         "bigip1.example.com",
+				"SYSLOG_IDENTIFIER": "tmm",
+				"PRIORITY":          "3",
+				"MESSAGE":           "01010028:3: No members available for pool /Common/p1",
+			} {
+				if got := first.Fields[key]; got != want {
+					t.Errorf("first %s = %q, want %q", key, got, want)
+				}
+			}
+			// The second message is stored xz-compressed.
+			if got, want := second.Fields["MESSAGE"], "01070727:5: Pool /Common/p1 member /Common/10.0.0.1:80 monitor status up."; got != want {
+				t.Errorf("second MESSAGE = %q, want %q", got, want)
+			}
+			if got := second.Fields["_HOSTNAME"]; got != "bigip1.example.com" {
+				t.Errorf("second _HOSTNAME = %q", got)
+			}
+		})
+	}
+}
+
+func TestJournalTruncated(t *testing.T) {
+	entries, err := readJournal(t, filepath.Join("testdata", "journal-truncated.journal"))
+	if err == nil {
+		t.Fatal("Entries on a truncated file returned no error")
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries before the cut, want 1", len(entries))
+	}
+}
+
+func TestJournalCorruptObjectSize(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "journal-regular.journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A zero size on the first object would keep the walk in place forever
+	// if it were not rejected.
+	start := binary.LittleEndian.Uint64(data[88:])
+	binary.LittleEndian.PutUint64(data[start+8:], 0)
+	path := filepath.Join(t.TempDir(), "corrupt.journal")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readJournal(t, path); err == nil {
+		t.Fatal("Entries on a corrupt file returned no error")
+	}
+}
+
+func TestOpenJournalRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not.journal")
+	if err := os.WriteFile(path, make([]byte, journalHeaderMinSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if j, err := OpenJournal(path); err == nil {
+		j.Close()
+		t.Fatal("OpenJournal accepted a file without the journal signature")
+	}
+}
+
+// journald-compact-zstd.journal.xz is the journal of a systemd 252 journald
+// namespace run with SYSTEMD_JOURNAL_COMPACT=1 and Compress=16, so that
+// every field longer than 16 bytes is zstd-compressed. It holds journald's
+// own start and stop messages around three entries sent over the native
+// protocol.
+func TestJournalSystemd(t *testing.T) {
+	compressed, err := os.Open(filepath.Join("testdata", "journald-compact-zstd.journal.xz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer compressed.Close()
+	r, err := xz.NewReader(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "system.journal")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	compact := j.compact
+	j.Close()
+	if !compact {
+		t.Error("compact flag not read from the header")
+	}
+
+	entries, err := readJournal(t, path)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	tests := []struct {
+		realtime   int64 // Microseconds since the epoch
+		identifier string
+		priority   string
+		message    string
+	}{
+		{1792376753271740, "systemd-journald", "6", "Journal started"},
+		{1792376753271976, "systemd-journald", "6", "System Journal (/var/log/journal/fed6b2924c424cf1b9a322f606b4de6d.goqkview) is 8.0M, max 4.0G, 3.9G free."},
+		{1792376760702994, "mcpd", "3", "01070727:5: Pool /Common/web member /Common/10.0.0.5:80 monitor status down."},
+		{1792376760703402, "restjavad", "6", "restjavad started"},
+		{1792376760703439, "restjavad", "4", "java.lang.IllegalStateException: boom\n\tat com.f5.Foo.bar(Foo.java:1)"},
+		{1792376761726255, "systemd-journald", "6", "Journal stopped"},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		e := entries[i]
+		if want := time.UnixMicro(tt.realtime); !e.Realtime.Equal(want) {
+			t.Errorf("entry %d realtime = %v, want %v", i, e.Realtime, want)
+		}
+		for key, want := range map[string]string{
+			"SYSLOG_IDENTIFIER": tt.identifier,
+			"PRIORITY":          tt.priority,
+			"MESSAGE":           tt.message,
+			"_HOSTNAME":         "vm",
+			"_MACHINE_ID":       "fed6b2924c424cf1b9a322f606b4de6d",
+		} {
+			if got := e.Fields[key]; got != want {
+				t.Errorf("entry %d %s = %q, want %q", i, key, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxBodyLines caps how many continuation lines are kept per entry so that a
+// runaway stack trace cannot blow up a single document.
+const maxBodyLines = 200
+
+// MultilineRule decides which physical lines continue the entry above them
+// instead of starting a new one.
+type MultilineRule struct {
+	Name     string
+	Indented bool           // Lines starting with whitespace continue the entry
+	Undated  bool           // Lines without a timestamp continue the entry
+	Continue *regexp.Regexp // Lines matching continue the entry even when they carry a date
+}
+
+var (
+	// Stack frames, chained causes and exception headers of a Java trace.
+	javaContinuationPattern = regexp.MustCompile(
+		`^\s+at\s|^\s*(Caused by|Suppressed):|^\s*\.\.\.\s+\d+\s+(more|common frames omitted)|^(?:[a-z][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error|Throwable)\b`)
+
+	javaRule = MultilineRule{
+		Name:     "java",
+		Indented: true,
+		Undated:  true,
+		Continue: javaContinuationPattern,
+	}
+	syslogRule = MultilineRule{
+		Name:     "syslog",
+		Indented: true,
+		Undated:  true,
+	}
+)
+
+// MultilineRuleFor returns the grouping rule for a profile's parser type.
+func MultilineRuleFor(parserType string) MultilineRule {
+	if parserType == ParserJava {
+		return javaRule
+	}
+	return syslogRule
+}
+
+// Continues reports whether line belongs to the entry above it. hasDate says
+// whether a timestamp was found on the line.
+func (r MultilineRule) Continues(line string, hasDate bool) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	if r.Continue != nil && r.Continue.MatchString(line) {
+		return true
+	}
+	if r.Indented && (line[0] == ' ' || line[0] == '\t') {
+		return true
+	}
+	return r.Undated && !hasDate
+}
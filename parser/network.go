@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type SelfIP struct {
+	Name         string
+	Partition    string
+	Path         string // Full path, e.g. /Common/self_ext
+	Address      string // CIDR with optional route domain, e.g. 10.1.1.5%2/24
+	VLAN         string
+	VLANPath     string // Full path of VLAN
+	TrafficGroup string
+}
+
+type VLANConfig struct {
+	Name      string
+	Partition string
+	Path      string
+	Tag       int
+}
+
+type RouteDomain struct {
+	Name  string
+	ID    int
+	VLANs []string
+}
+
+type VirtualAddress struct {
+	Name      string
+	Partition string
+	Path      string
+	Address   string
+	Mask      string
+	ARP       bool // false when "arp disabled"
+	ICMPEcho  string
+	Enabled   bool
+}
+
+var (
+	netObjectPattern = regexp.MustCompile(`^(net self|net vlan|net route-domain|ltm virtual-address)\s+(/\S+)\s*\{`)
+	netAttrPattern   = regexp.MustCompile(`^\s*([\w-]+)\s+(\S+)\s*$`)
+)
+
+// ParseNetworkConfig reads self IPs, VLANs, route domains and virtual
+// addresses from a config file into config. Network objects usually live in
+// bigip_base.conf, virtual addresses in bigip.conf. Self IPs, VLANs and
+// virtual addresses are keyed by full path; route domains by ID.
+func (c *BigIPConfig) ParseNetworkConfig(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("bigip: failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	c.initNetwork()
+
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	var kind, fullName string
+	var attrs map[string]string
+	var list []string
+	depth := 0
+	listDepth := -1
+
+	for scanner.Scan() {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if depth == 0 {
+			if matches := netObjectPattern.FindStringSubmatch(trimmed); matches != nil {
+				kind, fullName = matches[1], matches[2]
+				attrs = make(map[string]string)
+				list = nil
+			} else {
+				kind = ""
+			}
+		} else if kind != "" {
+			if depth == 1 && trimmed == "vlans {" {
+				listDepth = depth + 1
+			} else if depth == listDepth && trimmed != "}" {
+				list = append(list, CleanName(strings.TrimSuffix(trimmed, " { }")))
+			} else if depth == 1 {
+				if matches := netAttrPattern.FindStringSubmatch(trimmed); matches != nil {
+					attrs[matches[1]] = matches[2]
+				} else if trimmed == "disabled" || trimmed == "enabled" {
+					attrs[trimmed] = "true"
+				}
+			}
+		}
+
+		depth += strings.Count(trimmed, "{") - strings.Count(trimmed, "}")
+		if depth < listDepth {
+			listDepth = -1
+		}
+		if depth <= 0 {
+			if kind != "" {
+				c.addNetworkObject(kind, fullName, attrs, list)
+			}
+			kind = ""
+			depth = 0
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("bigip: error reading config: %w", err)
+	}
+	return nil
+}
+
+func (c *BigIPConfig) initNetwork() {
+	if c.SelfIPs == nil {
+		c.SelfIPs = make(map[string]*SelfIP)
+	}
+	if c.VLANs == nil {
+		c.VLANs = make(map[string]*VLANConfig)
+	}
+	if c.RouteDomains == nil {
+		c.RouteDomains = make(map[int]*RouteDomain)
+	}
+	if c.VirtualAddresses == nil {
+		c.VirtualAddresses = make(map[string]*VirtualAddress)
+	}
+}
+
+func (c *BigIPConfig) addNetworkObject(kind, fullName string, attrs map[string]string, list []string) {
+	name := CleanName(fullName)
+	partition := PartitionOf(fullName)
+
+	switch kind {
+	case "net self":
+		self := &SelfIP{
+			Name:         name,
+			Partition:    partition,
+			Path:         fullName,
+			Address:      attrs["address"],
+			VLAN:         CleanName(attrs["vlan"]),
+			TrafficGroup: CleanName(attrs["traffic-group"]),
+		}
+		if vlan := attrs["vlan"]; vlan != "" {
+			self.VLANPath = inPartition(vlan, partition)
+		}
+		c.SelfIPs[fullName] = self
+	case "net vlan":
+		tag, _ := strconv.Atoi(attrs["tag"])
+		c.VLANs[fullName] = &VLANConfig{Name: name, Partition: partition, Path: fullName, Tag: tag}
+	case "net route-domain":
+		id, err := strconv.Atoi(attrs["id"])
+		if err != nil {
+			id, err = strconv.Atoi(name)
+			if err != nil {
+				return
+			}
+		}
+		c.RouteDomains[id] = &RouteDomain{Name: name, ID: id, VLANs: list}
+	case "ltm virtual-address":
+		address := attrs["address"]
+		if address == "" {
+			address = name
+		}
+		c.VirtualAddresses[fullName] = &VirtualAddress{
+			Name:      name,
+			Partition: partition,
+			Path:      fullName,
+			Address:   address,
+			Mask:      attrs["mask"],
+			ARP:       attrs["arp"] != "disabled",
+			ICMPEcho:  attrs["icmp-echo"],
+			Enabled:   attrs["enabled"] != "no" && attrs["disabled"] == "",
+		}
+	}
+}
+
+// inPartition returns the full path of a reference written in an object of
+// partition; tmsh writes full paths, but hand-edited files may not.
+func inPartition(name, partition string) string {
+	if strings.HasPrefix(name, "/") || partition == "" {
+		return fullPath(name)
+	}
+	return "/" + partition + "/" + name
+}
+
+// VirtualAddressOf returns the virtual address a virtual server listens on:
+// the named one in the folder of its destination, else the one in that
+// partition whose name is the destination address.
+func (c *BigIPConfig) VirtualAddressOf(vs *VirtualServerConfig, dest Destination) (*VirtualAddress, bool) {
+	folder := "/Common/"
+	if vs.DestinationPath != "" {
+		folder = strings.TrimSuffix(vs.DestinationPath, vs.Destination)
+	}
+	if dest.Name != "" {
+		va, ok := c.VirtualAddresses[folder+dest.Name]
+		return va, ok
+	}
+	if !dest.Address.IsValid() {
+		return nil, false
+	}
+	key := dest.Address.String()
+	if dest.RouteDomain != 0 {
+		key += "%" + strconv.Itoa(dest.RouteDomain)
+	}
+	va, ok := c.VirtualAddresses[folder+key]
+	return va, ok
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseNetworkConfigPartitions(t *testing.T) {
+	conf := `net vlan /Tenant_A/external {
+    tag 100
+}
+net self /Tenant_A/self_ext {
+    address 10.1.1.5/24
+    vlan /Tenant_A/external
+}
+net self /Tenant_B/self_ext {
+    address 10.2.2.5%2/24
+    vlan external
+}
+ltm virtual-address /Tenant_A/vip {
+    address 10.1.1.10
+    arp disabled
+}
+ltm virtual-address /Tenant_B/vip {
+    address 10.2.2.10%2
+}
+`
+	path := filepath.Join(t.TempDir(), "bigip_base.conf")
+	if err := os.WriteFile(path, []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := &BigIPConfig{}
+	if err := c.ParseNetworkConfig(path); err != nil {
+		t.Fatalf("ParseNetworkConfig: %v", err)
+	}
+
+	tests := []struct {
+		path, address, vlan string
+	}{
+		{"/Tenant_A/self_ext", "10.1.1.5/24", "/Tenant_A/external"},
+		{"/Tenant_B/self_ext", "10.2.2.5%2/24", "/Tenant_B/external"},
+	}
+	for _, tt := range tests {
+		self, ok := c.SelfIPs[tt.path]
+		if !ok {
+			t.Errorf("self IP %s missing", tt.path)
+			continue
+		}
+		if self.Address != tt.address || self.VLANPath != tt.vlan {
+			t.Errorf("self IP %s = %s on %s, want %s on %s", tt.path, self.Address, self.VLANPath, tt.address, tt.vlan)
+		}
+	}
+	if _, ok := c.VLANs["/Tenant_A/external"]; !ok || len(c.VLANs) != 1 {
+		t.Errorf("VLANs = %v, want /Tenant_A/external", c.VLANs)
+	}
+	if a, b := c.VirtualAddresses["/Tenant_A/vip"], c.VirtualAddresses["/Tenant_B/vip"]; a == nil || b == nil || a.ARP || !b.ARP {
+		t.Errorf("virtual addresses = %v, want both vip with ARP disabled only in Tenant_A", c.VirtualAddresses)
+	}
+}
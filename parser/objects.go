@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"strings"
+
+	"goqkview/interfaces"
+)
+
+// Kinds of configuration object an ObjectRef names.
+const (
+	ObjectVirtual = "virtual"
+	ObjectPool    = "pool"
+	ObjectMember  = "member"
+	ObjectNode    = "node"
+	ObjectRule    = "rule"
+	ObjectMonitor = "monitor"
+	ObjectProfile = "profile"
+)
+
+// ObjectIndex finds the configuration objects a log message names. Objects
+// are indexed by full path, so /Common/app and /Tenant/app are distinct, and
+// every object matches by full path; virtual servers, pools and nodes in /Common
+// also by bare name, nodes by address, pool members by address:port and
+// virtual servers by destination. Matching is exact, so /Common/app does not
+// match /Common/app_v2.
+type ObjectIndex struct {
+	names map[string][]interfaces.ObjectRef
+}
+
+// NewObjectIndex indexes the objects of config, which may be nil.
+func NewObjectIndex(config *BigIPConfig) *ObjectIndex {
+	x := &ObjectIndex{names: make(map[string][]interfaces.ObjectRef)}
+	if config == nil {
+		return x
+	}
+
+	for path, vs := range config.ByPath.VirtualServers {
+		ref := interfaces.ObjectRef{Kind: ObjectVirtual, Name: vs.Name, Path: path}
+		x.add(path, ref)
+		x.addBare(vs.Name, vs.Partition, ref)
+		if !wildcardDestination(vs.Destination) {
+			x.add(vs.Destination, ref)
+		}
+	}
+	for path, pool := range config.ByPath.Pools {
+		ref := interfaces.ObjectRef{Kind: ObjectPool, Name: pool.Name, Path: path}
+		x.add(path, ref)
+		x.addBare(pool.Name, pool.Partition, ref)
+
+		for _, m := range pool.Members {
+			ref := interfaces.ObjectRef{Kind: ObjectMember, Name: m.Name, Path: m.Path, Pool: path}
+			x.add(m.Path, ref)
+			x.add(m.Name, ref)
+			if port := strings.TrimPrefix(m.Name, m.NodeName()); m.Address != "" && m.Address != m.NodeName() {
+				x.add(m.Address+port, ref)
+			}
+		}
+	}
+	for path, node := range config.ByPath.Nodes {
+		ref := interfaces.ObjectRef{Kind: ObjectNode, Name: node.Name, Path: path}
+		x.add(path, ref)
+		x.addBare(node.Name, node.Partition, ref)
+		// By address in every partition, also when the node is named after
+		// it, as /Tenant/10.1.1.5 usually is.
+		x.add(node.Address, ref)
+	}
+	for path, rule := range config.ByPath.Rules {
+		x.add(path, interfaces.ObjectRef{Kind: ObjectRule, Name: rule.Name, Path: path})
+	}
+	for path, monitor := range config.ByPath.Monitors {
+		x.add(path, interfaces.ObjectRef{Kind: ObjectMonitor, Name: monitor.Name, Path: path})
+	}
+	for path, profile := range config.ByPath.Profiles {
+		x.add(path, interfaces.ObjectRef{Kind: ObjectProfile, Name: profile.Name, Path: path})
+	}
+	return x
+}
+
+func (x *ObjectIndex) add(name string, ref interfaces.ObjectRef) {
+	if name == "" {
+		return
+	}
+	for _, r := range x.names[name] {
+		if r == ref {
+			return
+		}
+	}
+	x.names[name] = append(x.names[name], ref)
+}
+
+// addBare indexes the name without its path for objects in /Common, which
+// several daemons log that way.
+func (x *ObjectIndex) addBare(name, partition string, ref interfaces.ObjectRef) {
+	if partition == "" || partition == "Common" {
+		x.add(name, ref)
+	}
+}
+
+// Match returns the objects message names, in the order they appear. A
+// member is placed in the pools the message names, else in every pool that
+// has it.
+func (x *ObjectIndex) Match(message string) []interfaces.ObjectRef {
+	if len(x.names) == 0 {
+		return nil
+	}
+
+	var found []interfaces.ObjectRef
+	seen := make(map[interfaces.ObjectRef]bool)
+	tokens := strings.FieldsFunc(message, func(r rune) bool {
+		return strings.ContainsRune(" \t[](){}<>\"',;=", r)
+	})
+	for _, token := range tokens {
+		for _, ref := range x.names[strings.TrimRight(token, ".:")] {
+			if !seen[ref] {
+				seen[ref] = true
+				found = append(found, ref)
+			}
+		}
+	}
+
+	named := make(map[string]bool)
+	for _, ref := range found {
+		if ref.Kind == ObjectPool {
+			named[ref.Path] = true
+		}
+	}
+	inNamedPool := make(map[string]bool)
+	for _, ref := range found {
+		if ref.Kind == ObjectMember && named[ref.Pool] {
+			inNamedPool[ref.Path] = true
+		}
+	}
+
+	refs := found[:0]
+	for _, ref := range found {
+		if ref.Kind != ObjectMember || named[ref.Pool] || !inNamedPool[ref.Path] {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// wildcardDestination is true for destinations such as 0.0.0.0:0 or any:any
+// that do not identify a virtual server in a log line.
+func wildcardDestination(destination string) bool {
+	if destination == "" {
+		return true
+	}
+	for _, prefix := range []string{"0.0.0.0", "any", "::."} {
+		if strings.HasPrefix(destination, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"goqkview/interfaces"
 )
@@ -18,15 +19,22 @@ import (
 type Parser struct {
 	binaryChars map[byte]bool
 	dateOpts DateParseOptions
+	profiles []LogProfile
 }
 
 func NewParser(opts DateParseOptions) *Parser {
 	return &Parser{
 		binaryChars: buildBinaryCharMap(),
 		dateOpts:    opts,
+		profiles:    DefaultProfiles(),
 	}
 }
 
+// SetProfiles replaces the log-file profiles, e.g. with the result of LoadProfiles.
+func (p *Parser) SetProfiles(profiles []LogProfile) {
+	p.profiles = profiles
+}
+
 // Based on file/file library encoding detection.
 // https://github.com/file/file/blob/f2a6e7cb7db9b5fd86100403df6b2f830c7f22ba/src/encoding.c#L151-L228
 func buildBinaryCharMap() map[byte]bool {
@@ -48,18 +56,28 @@ type ProcessResult struct {
 	EntriesIndexed int
 	Errors         []error
 	BigIPConfig    *BigIPConfig
+	Device         *DeviceInfo
 }
 
 func (p *Parser) ProcessFile(ctx context.Context, filePath string, indexer interfaces.LogIndexer) (*ProcessResult, error) {
 	log.Printf("ProcessFile called with: %s", filePath)
 	result := &ProcessResult{}
 
-	extractDir, err := p.extract(filePath)
+	extractDir, newest, err := p.extract(filePath)
 	log.Printf("Extraction complete, extractDir: %s", extractDir)
 	if err != nil {
 		return nil, fmt.Errorf("parser: extraction failed: %w", err)
 	}
 
+	device, deviceErr := ParseDeviceInfo(extractDir)
+	if deviceErr != nil {
+		log.Printf("Warning: failed to parse device info: %v", deviceErr)
+		result.Errors = append(result.Errors, fmt.Errorf("device info parse: %w", deviceErr))
+	}
+	device.GeneratedAt = newest
+	result.Device = device
+	log.Printf("Device: %s, BIG-IP %s build %s", device.Hostname, device.Version, device.Build)
+
 	configPath := filepath.Join(extractDir, "config", "bigip.conf")
 	log.Printf("Looking for BigIP config at: %s", configPath)
 	if _, statErr := os.Stat(configPath); statErr == nil {
@@ -72,11 +90,45 @@ func (p *Parser) ProcessFile(ctx context.Context, filePath string, indexer inter
 			result.BigIPConfig = bigipConfig
 			log.Printf("Found %d virtual servers and %d pools",
 				len(bigipConfig.VirtualServers), len(bigipConfig.Pools))
+
+			runtime, runtimeErr := ParseRuntimeStatus(extractDir)
+			if runtimeErr != nil {
+				log.Printf("Warning: failed to parse runtime status: %v", runtimeErr)
+				result.Errors = append(result.Errors, fmt.Errorf("runtime status parse: %w", runtimeErr))
+			}
+			applied := bigipConfig.ApplyRuntime(runtime)
+			log.Printf("Applied runtime monitor status to %d pool members", applied)
+
+			for _, name := range []string{"bigip_base.conf", "bigip.conf"} {
+				netPath := filepath.Join(extractDir, "config", name)
+				if _, statErr := os.Stat(netPath); statErr != nil {
+					continue
+				}
+				if netErr := bigipConfig.ParseNetworkConfig(netPath); netErr != nil {
+					log.Printf("Warning: failed to parse network config %s: %v", name, netErr)
+					result.Errors = append(result.Errors, fmt.Errorf("network config parse: %w", netErr))
+				}
+			}
+			log.Printf("Found %d self IPs and %d virtual addresses",
+				len(bigipConfig.SelfIPs), len(bigipConfig.VirtualAddresses))
 		}
 	} else {
 		log.Printf("BigIP config not found at %s: %v", configPath, statErr)
 	}
 
+	// Resolve year-less timestamps against the capture time and zone-less
+	// ones in the device time zone, not those of the analysis machine.
+	dateOpts := p.dateOpts
+	if dateOpts.ReferenceTime.IsZero() && !device.GeneratedAt.IsZero() {
+		dateOpts.ReferenceTime = device.GeneratedAt
+	}
+	if dateOpts.Location == nil && device.Location != nil {
+		dateOpts.Location = device.Location
+	}
+
+	// Log lines are tied to the objects they name as they are indexed.
+	objects := NewObjectIndex(result.BigIPConfig)
+
 	logPath := filepath.Join(extractDir, "var", "log")
 	if _, statErr := os.Stat(logPath); os.IsNotExist(statErr) {
 		return nil, fmt.Errorf("parser: log directory not found: %s", logPath)
@@ -88,14 +140,27 @@ func (p *Parser) ProcessFile(ctx context.Context, filePath string, indexer inter
 			return nil // Continue walking
 		}
 
+		rel, relErr := filepath.Rel(logPath, path)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		profile := ProfileFor(p.profiles, rel)
+
 		if info.IsDir() {
-			if info.Name() == "journal" {
+			if profile.Exclude {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		if strings.Contains(info.Name(), "audit") || info.Size() == 0 {
+		if profile.Exclude || info.Size() == 0 {
+			return nil
+		}
+
+		if profile.Parser == ParserJournal {
+			entries, errs := p.parseJournalFile(ctx, path, filePath, dateOpts, profile)
+			result.Errors = append(result.Errors, errs...)
+			p.index(ctx, entries, device, objects, indexer, result)
 			return nil
 		}
 
@@ -108,17 +173,9 @@ func (p *Parser) ProcessFile(ctx context.Context, filePath string, indexer inter
 			return nil
 		}
 
-		entries, errs := p.parseLogFile(ctx, path, filePath)
-		result.EntriesFound += len(entries)
+		entries, errs := p.parseLogFile(ctx, path, filePath, dateOpts, profile)
 		result.Errors = append(result.Errors, errs...)
-
-		for _, entry := range entries {
-			if error := indexer.Index(ctx, entry); err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("indexing failed: %w", error))
-			} else {
-				result.EntriesIndexed++
-			}
-		}
+		p.index(ctx, entries, device, objects, indexer, result)
 
 		return nil
 	})
@@ -130,16 +187,37 @@ func (p *Parser) ProcessFile(ctx context.Context, filePath string, indexer inter
 	return result, nil
 }
 
-func (p *Parser) extract(filePath string) (string, error) {
+func (p *Parser) index(ctx context.Context, entries []interfaces.LogEntry, device *DeviceInfo, objects *ObjectIndex, indexer interfaces.LogIndexer, result *ProcessResult) {
+	result.EntriesFound += len(entries)
+	for _, entry := range entries {
+		entry.Hostname = device.Hostname
+		if entry.Message != "" {
+			entry.Objects = objects.Match(entry.Message)
+		} else {
+			entry.Objects = objects.Match(entry.Line)
+		}
+		if err := indexer.Index(ctx, entry); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("indexing failed: %w", err))
+		} else {
+			result.EntriesIndexed++
+		}
+	}
+}
+
+// extract unpacks the archive and returns the directory together with the
+// newest modification time found in the tar headers.
+func (p *Parser) extract(filePath string) (string, time.Time, error) {
+	var newest time.Time
+
 	file, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return "", newest, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
 	gzipReader, err := gzip.NewReader(file)
 	if err != nil {
-		return "", fmt.Errorf("failed to create gzip reader: %w", err)
+		return "", newest, fmt.Errorf("failed to create gzip reader: %w", err)
 	}
 	defer gzipReader.Close()
 
@@ -154,30 +232,33 @@ func (p *Parser) extract(filePath string) (string, error) {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("failed to read tar header: %w", err)
+			return "", newest, fmt.Errorf("failed to read tar header: %w", err)
 		}
 
 		destPath := filepath.Join(destDir, header.Name)
 		destDirPath := filepath.Dir(destPath)
 
 		if err := os.MkdirAll(destDirPath, os.ModePerm); err != nil {
-			return "", fmt.Errorf("failed to create directory %s: %w", destDirPath, err)
+			return "", newest, fmt.Errorf("failed to create directory %s: %w", destDirPath, err)
 		}
 
 		if header.Typeflag == tar.TypeReg {
+			if header.ModTime.After(newest) {
+				newest = header.ModTime
+			}
 			dest, err := os.Create(destPath)
 			if err != nil {
-				return "", fmt.Errorf("failed to create file %s: %w", destPath, err)
+				return "", newest, fmt.Errorf("failed to create file %s: %w", destPath, err)
 			}
 			if _, err := io.Copy(dest, tarReader); err != nil {
 				dest.Close()
-				return "", fmt.Errorf("failed to write file %s: %w", destPath, err)
+				return "", newest, fmt.Errorf("failed to write file %s: %w", destPath, err)
 			}
 			dest.Close()
 		}
 	}
 
-	return destDir, nil
+	return destDir, newest, nil
 }
 
 func (p *Parser) isBinaryFile(path string) (bool, error) {
@@ -207,7 +288,7 @@ func GetExtractDir(filePath string) string {
 	return destDir
 }
 
-func (p *Parser) parseLogFile(ctx context.Context, path, source string) ([]interfaces.LogEntry, []error) {
+func (p *Parser) parseLogFile(ctx context.Context, path, source string, dateOpts DateParseOptions, profile LogProfile) ([]interfaces.LogEntry, []error) {
 	var entries []interfaces.LogEntry
 	var errors []error
 
@@ -219,7 +300,30 @@ func (p *Parser) parseLogFile(ctx context.Context, path, source string) ([]inter
 	}
 	defer file.Close()
 
+	// Year-less timestamps are resolved once the whole file has been seen.
+	type yearless struct {
+		entry  int
+		wall   time.Time
+		offset int
+	}
+	var pending []yearless
+	years := newYearTracker(dateOpts)
+
+	// A dated line opens a record; continuation lines are attached to it as
+	// the body. open is the index of the record in entries, or -1 when the
+	// record is not kept.
+	rule := MultilineRuleFor(profile.Parser)
+	facility := profile.Facility
+	if facility == "" {
+		facility = Facility(path)
+	}
+	dateOpts.Format = profile.DateFormat
+	structured := profile.Parser != ParserJava && profile.Parser != ParserText
+	open, bodyLines, lineNumber := -1, 0, 0
+
 	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
@@ -228,29 +332,93 @@ func (p *Parser) parseLogFile(ctx context.Context, path, source string) ([]inter
 		}
 
 		line := scanner.Text()
+		lineNumber++
 
-		status, hasStatus := ParseStatus(line)
-		if !hasStatus {
+		timestamp, isYearless, hasDate := parseDate(line, dateOpts)
+		if rule.Continues(line, hasDate) {
+			if open >= 0 {
+				bodyLines++
+				entries[open].Body = appendBody(entries[open].Body, line, bodyLines)
+			}
 			continue
 		}
-
-		timestamp, hasDate := ParseDate(line, p.dateOpts)
+		open = -1
 		if !hasDate {
 			continue
 		}
 
+		// Every dated line counts towards year rollover, not only the ones kept.
+		offset, backwards := 0, false
+		if isYearless {
+			offset, backwards = years.observe(timestamp)
+		}
+
+		var fields SyslogFields
+		isSyslog := false
+		if structured {
+			fields, isSyslog = ParseSyslog(line)
+		}
+		syslog := &fields
+		if !isSyslog {
+			syslog = nil
+		}
+		status, method := ClassifySeverity(line, syslog)
+		if status == "" {
+			continue
+		}
+
+		if isYearless {
+			pending = append(pending, yearless{entry: len(entries), wall: timestamp, offset: offset})
+		}
+		open, bodyLines = len(entries), 0
 		entries = append(entries, interfaces.LogEntry{
-			Path:      path,
-			Line:      line,
-			Status:    status,
-			Timestamp: timestamp,
-			Source:    source,
+			Path:       path,
+			LineNumber: lineNumber,
+			Line:       line,
+			Status:     status,
+			Timestamp:  timestamp.UTC(),
+			UTCOffset:  timestamp.Format("-07:00"),
+			Source:     source,
+			OutOfOrder: backwards,
+			Facility:   facility,
+
+			SeverityMethod: method,
 		})
+		if isSyslog {
+			e := &entries[open]
+			e.Host = fields.Host
+			e.Severity = fields.Severity
+			e.Process = fields.Process
+			e.PID = fields.PID
+			e.MessageID = fields.MessageID
+			e.Message = fields.Message
+			if profile.Parser == ParserAudit {
+				e.Audit, _ = ParseAudit(fields.Process, fields.Message)
+			}
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		errors = append(errors, fmt.Errorf("scanner error for %s: %w", path, err))
 	}
 
+	for _, p := range pending {
+		timestamp := years.resolve(p.wall, p.offset)
+		entries[p.entry].Timestamp = timestamp.UTC()
+		entries[p.entry].UTCOffset = timestamp.Format("-07:00")
+	}
+
 	return entries, errors
 }
+
+func appendBody(body, line string, n int) string {
+	switch {
+	case n > maxBodyLines+1:
+		return body
+	case n == maxBodyLines+1:
+		return body + "\n..."
+	case body == "":
+		return line
+	}
+	return body + "\n" + line
+}
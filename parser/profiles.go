@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parser types a profile can select.
+const (
+	ParserAuto    = "auto"    // Syslog header, restjavad header, then level keywords
+	ParserSyslog  = "syslog"  // BIG-IP syslog lines; level keywords only for lines without a header
+	ParserJava    = "java"    // restjavad/restnoded [LEVEL][thread][time] lines with stack traces
+	ParserText    = "text"    // Unstructured text; level keywords anywhere in the line
+	ParserJournal = "journal" // systemd journal files (binary); the date format does not apply
+	ParserAudit   = "audit"   // BIG-IP audit log: syslog lines carrying tmsh commands, mcpd transactions and logins
+)
+
+// Date formats a profile can select.
+const (
+	DateFormatAuto   = "auto"   // Any known timestamp anywhere in the line
+	DateFormatSyslog = "syslog" // Timestamp at the start of the line
+	DateFormatJava   = "java"   // Timestamp in the third bracket of the restjavad header
+)
+
+// LogProfile says how the files matching a set of globs under var/log are
+// read. Globs without a slash match the base name of a file or directory;
+// globs with one match the path relative to var/log.
+type LogProfile struct {
+	Name       string   `yaml:"name"`
+	Match      []string `yaml:"match"`
+	Exclude    bool     `yaml:"exclude"`
+	Parser     string   `yaml:"parser"`
+	DateFormat string   `yaml:"dateFormat"`
+	Facility   string   `yaml:"facility"` // Empty means the file name without rotation suffixes
+}
+
+// DefaultProfiles is tried in order; the first profile with a matching glob wins.
+func DefaultProfiles() []LogProfile {
+	return []LogProfile{
+		{Name: "journal", Match: []string{"*.journal", "*.journal~"}, Parser: ParserJournal, Facility: "journal"},
+		{Name: "audit", Match: []string{"audit*"}, Parser: ParserAudit, DateFormat: DateFormatSyslog, Facility: "audit"},
+		{Name: "other-audit", Match: []string{"*audit*"}, Exclude: true},
+		{Name: "ltm", Match: []string{"ltm*"}, Parser: ParserSyslog, DateFormat: DateFormatSyslog, Facility: "ltm"},
+		{Name: "gtm", Match: []string{"gtm*"}, Parser: ParserSyslog, DateFormat: DateFormatSyslog, Facility: "gtm"},
+		{Name: "apm", Match: []string{"apm*"}, Parser: ParserSyslog, DateFormat: DateFormatSyslog, Facility: "apm"},
+		{Name: "asm", Match: []string{"asm*"}, Parser: ParserSyslog, DateFormat: DateFormatSyslog, Facility: "asm"},
+		{Name: "afm", Match: []string{"afm*"}, Parser: ParserSyslog, DateFormat: DateFormatSyslog, Facility: "afm"},
+		{Name: "tmm", Match: []string{"tmm*"}, Parser: ParserSyslog, DateFormat: DateFormatSyslog, Facility: "tmm"},
+		{
+			Name:       "system",
+			Match:      []string{"messages*", "daemon.log*", "kern.log*", "user.log*", "secure*", "boot.log*", "liveinstall.log*"},
+			Parser:     ParserSyslog,
+			DateFormat: DateFormatSyslog,
+		},
+		{
+			Name:       "restjavad",
+			Match:      []string{"restjavad*", "restnoded*", "icrd*", "catalina*", "tomcat*", "java*"},
+			Parser:     ParserJava,
+			DateFormat: DateFormatJava,
+		},
+		{Name: "default", Match: []string{"*"}, Parser: ParserAuto, DateFormat: DateFormatAuto},
+	}
+}
+
+type profileFile struct {
+	Profiles []LogProfile `yaml:"profiles"`
+}
+
+// LoadProfiles reads profiles from a YAML file and combines them with the
+// defaults: a profile named like a default replaces it in place, any other
+// is tried before all defaults.
+func LoadProfiles(path string) ([]LogProfile, error) {
+	profiles := DefaultProfiles()
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parser: failed to read profiles %s: %w", path, err)
+	}
+	var f profileFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parser: invalid profiles %s: %w", path, err)
+	}
+
+	var added []LogProfile
+	for _, profile := range f.Profiles {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("parser: profiles %s: %w", path, err)
+		}
+		replaced := false
+		for i := range profiles {
+			if profiles[i].Name == profile.Name {
+				profiles[i] = profile
+				replaced = true
+			}
+		}
+		if !replaced {
+			added = append(added, profile)
+		}
+	}
+	return append(added, profiles...), nil
+}
+
+func (lp LogProfile) validate() error {
+	if lp.Name == "" {
+		return fmt.Errorf("profile without a name")
+	}
+	if len(lp.Match) == 0 {
+		return fmt.Errorf("profile %s has no match patterns", lp.Name)
+	}
+	for _, pattern := range lp.Match {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("profile %s: invalid pattern %q: %w", lp.Name, pattern, err)
+		}
+	}
+	switch lp.Parser {
+	case "", ParserAuto, ParserSyslog, ParserJava, ParserText, ParserJournal, ParserAudit:
+	default:
+		return fmt.Errorf("profile %s: unknown parser %q", lp.Name, lp.Parser)
+	}
+	switch lp.DateFormat {
+	case "", DateFormatAuto, DateFormatSyslog, DateFormatJava:
+	default:
+		return fmt.Errorf("profile %s: unknown date format %q", lp.Name, lp.DateFormat)
+	}
+	return nil
+}
+
+// ProfileFor returns the first profile matching rel, a path relative to
+// var/log. Files no profile matches are read with ParserAuto.
+func ProfileFor(profiles []LogProfile, rel string) LogProfile {
+	rel = filepath.ToSlash(rel)
+	base := filepath.Base(rel)
+	for _, profile := range profiles {
+		for _, pattern := range profile.Match {
+			target := base
+			if strings.Contains(pattern, "/") {
+				target = rel
+			}
+			if ok, _ := filepath.Match(pattern, target); ok {
+				return profile
+			}
+		}
+	}
+	return LogProfile{Name: "default", Parser: ParserAuto, DateFormat: DateFormatAuto}
+}
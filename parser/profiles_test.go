@@ -0,0 +1,33 @@
+package parser
+
+import "testing"
+
+func TestProfileFor(t *testing.T) {
+	tests := []struct {
+		rel       string
+		profile   string
+		multiline string
+	}{
+		{"ltm", "ltm", "syslog"},
+		{"ltm.1.gz", "ltm", "syslog"},
+		{"journal/system.journal", "journal", "syslog"},
+		{"restjavad.0.log", "restjavad", "java"},
+		{"restnoded/restnoded.log", "restjavad", "java"},
+		{"tomcat/catalina.out", "restjavad", "java"},
+		{"tomcat/tomcat.log", "restjavad", "java"},
+		{"java_gc.log", "restjavad", "java"},
+		{"audit", "audit", "syslog"},
+		{"secure", "system", "syslog"},
+		{"webui.log", "default", "syslog"},
+	}
+	profiles := DefaultProfiles()
+	for _, tt := range tests {
+		profile := ProfileFor(profiles, tt.rel)
+		if profile.Name != tt.profile {
+			t.Errorf("ProfileFor(%q) = %s, want %s", tt.rel, profile.Name, tt.profile)
+		}
+		if rule := MultilineRuleFor(profile.Parser); rule.Name != tt.multiline {
+			t.Errorf("MultilineRuleFor(%q) = %s, want %s", tt.rel, rule.Name, tt.multiline)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type MemberStatus string
+
+const (
+	MemberStatusUnknown       MemberStatus = ""
+	MemberStatusUp            MemberStatus = "up"
+	MemberStatusDown          MemberStatus = "down"
+	MemberStatusUnchecked     MemberStatus = "unchecked"
+	MemberStatusForcedOffline MemberStatus = "forced-offline"
+)
+
+type MemberRuntime struct {
+	Pool   string // Full path of the pool
+	Member string // Cleaned node:port
+	Status MemberStatus
+	Reason string
+	Source string // File the status was read from
+}
+
+type RuntimeStatus struct {
+	Members map[string]*MemberRuntime // Keyed by pool + "|" + member
+}
+
+// Runtime files written by qkview: mcpd and statistics database dumps at the
+// archive root, plus any captured tmsh/tmctl command output.
+var runtimeXMLFiles = []string{"mcp_module.xml", "stat_module.xml"}
+
+var (
+	tmshPoolPattern   = regexp.MustCompile(`Ltm::Pool:\s+(\S+)`)
+	tmshMemberPattern = regexp.MustCompile(`Ltm::Pool Member:\s+(\S+)`)
+	tmshFieldPattern  = regexp.MustCompile(`^[\s|]*(Availability|State|Reason|Monitor Status)\s*:\s*(.+?)\s*$`)
+)
+
+func runtimeKey(pool, member string) string {
+	return pool + "|" + member
+}
+
+func ParseRuntimeStatus(extractDir string) (*RuntimeStatus, error) {
+	rs := &RuntimeStatus{Members: make(map[string]*MemberRuntime)}
+
+	for _, name := range runtimeXMLFiles {
+		path := filepath.Join(extractDir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := rs.parseXML(path); err != nil {
+			return rs, fmt.Errorf("runtime: failed to parse %s: %w", name, err)
+		}
+	}
+
+	err := filepath.Walk(extractDir, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path == filepath.Join(extractDir, "var", "log") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := strings.ToLower(info.Name())
+		if strings.Contains(name, "tmsh") || strings.Contains(name, "tmctl") {
+			if err := rs.parseTmshText(path); err != nil {
+				return fmt.Errorf("runtime: failed to parse %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+
+	return rs, err
+}
+
+// parseXML streams an mcpd/stat dump and picks up every pool member record,
+// whatever the schema version calls its status fields.
+func (rs *RuntimeStatus) parseXML(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := xml.NewDecoder(file)
+	decoder.Strict = false
+
+	var record map[string]string
+	var recordDepth, depth int
+	var text strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			text.Reset()
+			if record == nil {
+				switch t.Name.Local {
+				case "pool_member", "pool_member_status", "pool_member_stat":
+					record = make(map[string]string)
+					recordDepth = depth
+				}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if record != nil {
+				if depth == recordDepth {
+					rs.addXMLRecord(record, filepath.Base(path))
+					record = nil
+				} else if value := strings.TrimSpace(text.String()); value != "" {
+					record[t.Name.Local] = value
+				}
+			}
+			text.Reset()
+			depth--
+		}
+	}
+}
+
+func (rs *RuntimeStatus) addXMLRecord(record map[string]string, source string) {
+	pool := record["pool_name"]
+	if pool != "" {
+		pool = fullPath(pool)
+	}
+	member := firstNonEmpty(record["member_name"], record["name"])
+	if node := firstNonEmpty(record["node_name"], record["addr"], record["address"]); node != "" && record["port"] != "" {
+		member = node + ":" + record["port"]
+	}
+	member = CleanName(member)
+	if pool == "" || member == "" {
+		return
+	}
+
+	status := normalizeMemberStatus(firstNonEmpty(
+		record["monitor_status"],
+		record["status.availability_state"],
+		record["availability_state"],
+		record["monitor_state"],
+	))
+	if strings.Contains(strings.ToLower(record["session_status"]), "forced") {
+		status = MemberStatusForcedOffline
+	}
+	if status == MemberStatusUnknown {
+		return
+	}
+
+	rs.set(&MemberRuntime{
+		Pool:   pool,
+		Member: member,
+		Status: status,
+		Reason: firstNonEmpty(record["status.status_reason"], record["status_reason"]),
+		Source: source,
+	})
+}
+
+func (rs *RuntimeStatus) parseTmshText(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var pool string
+	var current *MemberRuntime
+	flush := func() {
+		if current != nil && current.Status != MemberStatusUnknown {
+			rs.set(current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if matches := tmshMemberPattern.FindStringSubmatch(line); matches != nil {
+			flush()
+			current = &MemberRuntime{Pool: pool, Member: CleanName(matches[1]), Source: filepath.Base(path)}
+			continue
+		}
+		if matches := tmshPoolPattern.FindStringSubmatch(line); matches != nil {
+			flush()
+			pool = fullPath(matches[1])
+			continue
+		}
+		if current == nil || current.Pool == "" {
+			continue
+		}
+
+		matches := tmshFieldPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		switch matches[1] {
+		case "Availability", "Monitor Status":
+			if status := normalizeMemberStatus(matches[2]); status != MemberStatusUnknown && current.Status != MemberStatusForcedOffline {
+				current.Status = status
+			}
+		case "State":
+			if strings.Contains(matches[2], "forced-offline") || strings.Contains(matches[2], "forced offline") {
+				current.Status = MemberStatusForcedOffline
+			}
+		case "Reason":
+			current.Reason = matches[2]
+		}
+	}
+	flush()
+
+	return scanner.Err()
+}
+
+// set records m over any earlier source for the same member. A reason is
+// carried over only while the status stays the same; it explains that
+// status, not the new one.
+func (rs *RuntimeStatus) set(m *MemberRuntime) {
+	key := runtimeKey(m.Pool, m.Member)
+	if existing, ok := rs.Members[key]; ok && m.Reason == "" && m.Status == existing.Status {
+		m.Reason = existing.Reason
+	}
+	rs.Members[key] = m
+}
+
+func normalizeMemberStatus(value string) MemberStatus {
+	v := strings.ToLower(strings.TrimSpace(value))
+	for _, prefix := range []string{"monitor_status_", "monitor_state_", "availability_status_"} {
+		v = strings.TrimPrefix(v, prefix)
+	}
+
+	// Match whole words, negatives first, so "unavailable" is not read as
+	// available nor "down-by-irule" as anything but down.
+	words := strings.FieldsFunc(v, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '(' || r == ')'
+	})
+	has := func(tokens ...string) bool {
+		for _, w := range words {
+			for _, t := range tokens {
+				if w == t {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("forced"):
+		return MemberStatusForcedOffline
+	case has("down", "offline", "unavailable", "red"):
+		return MemberStatusDown
+	case has("unchecked", "unknown", "checking", "blue"):
+		return MemberStatusUnchecked
+	case has("up", "available", "green"):
+		return MemberStatusUp
+	}
+	return MemberStatusUnknown
+}
+
+// ApplyRuntime copies monitor status onto the matching configured members.
+func (c *BigIPConfig) ApplyRuntime(rs *RuntimeStatus) int {
+	if rs == nil {
+		return 0
+	}
+
+	applied := 0
+	for path, pool := range c.ByPath.Pools {
+		for i := range pool.Members {
+			m := &pool.Members[i]
+			if rt, ok := rs.Members[runtimeKey(path, m.Name)]; ok {
+				m.Status = rt.Status
+				m.StatusReason = rt.Reason
+				applied++
+			}
+		}
+	}
+	return applied
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
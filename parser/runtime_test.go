@@ -0,0 +1,29 @@
+package parser
+
+import "testing"
+
+func TestRuntimeStatusReason(t *testing.T) {
+	const pool, member = "/Common/web", "10.0.0.5:80"
+	tests := []struct {
+		name       string
+		status     MemberStatus
+		reason     string
+		wantReason string
+	}{
+		{"same status keeps the reason", MemberStatusDown, "", "Pool member has been marked down by a monitor"},
+		{"new reason replaces it", MemberStatusDown, "Forced down", "Forced down"},
+		{"changed status drops it", MemberStatusUp, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &RuntimeStatus{Members: make(map[string]*MemberRuntime)}
+			rs.set(&MemberRuntime{Pool: pool, Member: member, Status: MemberStatusDown, Reason: "Pool member has been marked down by a monitor"})
+			rs.set(&MemberRuntime{Pool: pool, Member: member, Status: tt.status, Reason: tt.reason})
+
+			got := rs.Members[runtimeKey(pool, member)]
+			if got.Status != tt.status || got.Reason != tt.wantReason {
+				t.Errorf("member = %s %q, want %s %q", got.Status, got.Reason, tt.status, tt.wantReason)
+			}
+		})
+	}
+}
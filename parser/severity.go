@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// How the status of an entry was determined, recorded in LogEntry.SeverityMethod.
+const (
+	SeveritySyslog    = "syslog"     // Level keyword after the host name
+	SeverityMessageID = "message-id" // Level digit of the BIG-IP message ID (01070638:5:)
+	SeverityHeader    = "header"     // Bracketed level of restjavad/restnoded lines ([SEVERE])
+	SeverityKeyword   = "keyword"    // Level word anywhere in an unstructured line
+	SeverityJournal   = "priority"   // PRIORITY field of a systemd journal entry
+)
+
+// syslogStatuses maps the eight syslog levels to entry statuses.
+var syslogStatuses = map[string]string{
+	"emerg":   "EMERGENCY",
+	"alert":   "ALERT",
+	"crit":    "CRITICAL",
+	"err":     "ERROR",
+	"warning": "WARNING",
+	"notice":  "NOTICE",
+	"info":    "INFO",
+	"debug":   "DEBUG",
+}
+
+// messageIDStatuses is indexed by the syslog level digit.
+var messageIDStatuses = []string{"EMERGENCY", "ALERT", "CRITICAL", "ERROR", "WARNING", "NOTICE", "INFO", "DEBUG"}
+
+// Java logging levels as written by restjavad. SEVERE is kept as is; the
+// analyzers treat it like ERROR.
+var headerStatuses = map[string]string{
+	"SEVERE":  "SEVERE",
+	"WARNING": "WARNING",
+	"INFO":    "INFO",
+	"CONFIG":  "DEBUG",
+	"FINE":    "DEBUG",
+	"FINER":   "DEBUG",
+	"FINEST":  "DEBUG",
+}
+
+var headerLevelPattern = regexp.MustCompile(`^\[(SEVERE|WARNING|INFO|CONFIG|FINE|FINER|FINEST)\]`)
+
+// ClassifySeverity returns the status of a line and the method that found
+// it. fields is the parsed syslog header, nil when the line is not in syslog
+// format. Only lines with no structure at all fall back to matching level
+// words anywhere in the text; a structured line without a level is left
+// unclassified.
+func ClassifySeverity(line string, fields *SyslogFields) (string, string) {
+	if fields != nil {
+		if status, ok := syslogStatuses[fields.Severity]; ok {
+			return status, SeveritySyslog
+		}
+		if level, err := strconv.Atoi(fields.level); err == nil && level >= 0 && level < len(messageIDStatuses) {
+			return messageIDStatuses[level], SeverityMessageID
+		}
+		return "", ""
+	}
+
+	if matches := headerLevelPattern.FindStringSubmatch(line); matches != nil {
+		return headerStatuses[matches[1]], SeverityHeader
+	}
+
+	if status, ok := ParseStatus(line); ok {
+		return status, SeverityKeyword
+	}
+	return "", ""
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SyslogFields are the parts of a BIG-IP syslog line such as
+//
+//	Dec 31 10:00:00 bigip1 err mcpd[5511]: 01010253:3: Certificate ... expired
+type SyslogFields struct {
+	Host      string
+	Severity  string // Keyword as written: emerg, alert, crit, err, warning, notice, info, debug
+	Process   string
+	PID       int
+	MessageID string // BIG-IP message ID, e.g. 01010253
+	Message   string // Text after the process and message ID
+
+	level string // Syslog level digit that follows the message ID
+}
+
+var syslogPattern = regexp.MustCompile(
+	`^(?:[A-Z][a-z]{2}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T\S+)\s+(\S+)\s+` +
+		`(?:(emerg|alert|crit|err|warning|notice|info|debug)\s+)?` +
+		`([^\s\[:]+)(?:\[(\d+)\])?:\s*` +
+		`(?:([0-9a-fA-F]{8}):(\d):\s*)?(.*)$`)
+
+// ParseSyslog splits a syslog line into its fields. Lines in other formats,
+// such as restjavad's bracketed header, are reported as not matching.
+func ParseSyslog(line string) (SyslogFields, bool) {
+	matches := syslogPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return SyslogFields{}, false
+	}
+
+	fields := SyslogFields{
+		Host:      matches[1],
+		Severity:  matches[2],
+		Process:   matches[3],
+		MessageID: strings.ToUpper(matches[5]),
+		Message:   strings.TrimSpace(matches[7]),
+		level:     matches[6],
+	}
+	if matches[4] != "" {
+		fields.PID, _ = strconv.Atoi(matches[4])
+	}
+	return fields, true
+}
+
+// Facility names the log a file belongs to, without rotation and format
+// suffixes: var/log/ltm.1 and var/log/ltm.2.gz are both "ltm",
+// restjavad.0.log is "restjavad".
+func Facility(path string) string {
+	name := filepath.Base(path)
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
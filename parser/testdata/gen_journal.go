@@ -0,0 +1,158 @@
+//go:build ignore
+
+// gen_journal writes the small journal files the parser tests read:
+//
+//	go run gen_journal.go
+//
+// Only the objects the reader walks are written: data objects followed by
+// the entries that point at them, with no hash tables or entry arrays.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"os"
+
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	headerSize = 256
+
+	flagXZ      = 1 << 0
+	flagCompact = 1 << 4
+
+	objectData  = 1
+	objectEntry = 3
+)
+
+type field struct {
+	data string
+	xz   bool
+}
+
+type record struct {
+	realtime uint64 // Microseconds since the epoch
+	fields   []field
+}
+
+var records = []record{
+	{1760000000000000, []field{
+		{data: "_HOSTNAME=bigip1.example.com"},
+		{data: "SYSLOG_IDENTIFIER=tmm"},
+		{data: "PRIORITY=3"},
+		{data: "MESSAGE=01010028:3: No members available for pool /Common/p1"},
+	}},
+	{1760000060000000, []field{
+		{data: "_HOSTNAME=bigip1.example.com"},
+		{data: "SYSLOG_IDENTIFIER=mcpd"},
+		{data: "PRIORITY=6"},
+		{data: "MESSAGE=01070727:5: Pool /Common/p1 member /Common/10.0.0.1:80 monitor status up.", xz: true},
+	}},
+}
+
+func main() {
+	regular := build(false)
+	compact := build(true)
+	write("journal-regular.journal", regular)
+	write("journal-compact.journal", compact)
+	// Cut in the middle of the last entry, the way a copy taken while
+	// journald writes can end.
+	write("journal-truncated.journal", regular[:len(regular)-20])
+}
+
+func build(compact bool) []byte {
+	var arena bytes.Buffer
+	offsets := make(map[string]uint64)
+	flags := uint32(0)
+	if compact {
+		flags |= flagCompact
+	}
+
+	payloadAt := 64
+	if compact {
+		payloadAt = 72
+	}
+	for _, r := range records {
+		for _, f := range r.fields {
+			if _, ok := offsets[f.data]; ok {
+				continue
+			}
+			payload := []byte(f.data)
+			objectFlags := byte(0)
+			if f.xz {
+				payload = compress(payload)
+				objectFlags = flagXZ
+				flags |= flagXZ
+			}
+			obj := make([]byte, payloadAt, payloadAt+len(payload))
+			obj = append(obj, payload...)
+			obj[0] = objectData
+			obj[1] = objectFlags
+			offsets[f.data] = append8(&arena, obj)
+		}
+	}
+
+	var tail uint64
+	for i, r := range records {
+		itemSize := 16
+		if compact {
+			itemSize = 4
+		}
+		obj := make([]byte, 64+itemSize*len(r.fields))
+		obj[0] = objectEntry
+		binary.LittleEndian.PutUint64(obj[16:], uint64(i+1))
+		binary.LittleEndian.PutUint64(obj[24:], r.realtime)
+		for j, f := range r.fields {
+			pos := 64 + j*itemSize
+			if compact {
+				binary.LittleEndian.PutUint32(obj[pos:], uint32(offsets[f.data]))
+			} else {
+				binary.LittleEndian.PutUint64(obj[pos:], offsets[f.data])
+			}
+		}
+		tail = append8(&arena, obj)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, "LPKSHHRH")
+	binary.LittleEndian.PutUint32(header[12:], flags)
+	binary.LittleEndian.PutUint64(header[88:], headerSize)
+	binary.LittleEndian.PutUint64(header[96:], uint64(arena.Len()))
+	binary.LittleEndian.PutUint64(header[136:], tail)
+	return append(header, arena.Bytes()...)
+}
+
+// append8 writes obj with its size set, pads it to 8 bytes and returns its
+// offset in the file.
+func append8(arena *bytes.Buffer, obj []byte) uint64 {
+	offset := headerSize + uint64(arena.Len())
+	binary.LittleEndian.PutUint64(obj[8:], uint64(len(obj)))
+	arena.Write(obj)
+	for arena.Len()%8 != 0 {
+		arena.WriteByte(0)
+	}
+	return offset
+}
+
+func compress(data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := xz.NewWriter(&buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func write(name string, data []byte) {
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
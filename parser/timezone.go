@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	// Device zones must resolve even where the analysis host has no zoneinfo.
+	_ "time/tzdata"
+)
+
+// deviceLocation resolves the device time zone: the configured zone name
+// first, then the TZif file at etc/localtime inside the qkview.
+func deviceLocation(extractDir, name string) *time.Location {
+	if name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(extractDir, "etc", "localtime"))
+	if err != nil {
+		return nil
+	}
+	if name == "" {
+		name = "localtime"
+	}
+	loc, err := time.LoadLocationFromTZData(name, data)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
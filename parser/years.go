@@ -0,0 +1,66 @@
+package parser
+
+import "time"
+
+const (
+	// rolloverGap is how far the wall clock has to jump between two lines
+	// before it counts as crossing a year boundary (Dec 31 -> Jan 1).
+	rolloverGap = 180 * 24 * time.Hour
+	// backwardsTolerance absorbs syslog buffering and the DST fall-back hour.
+	backwardsTolerance = 2 * time.Hour
+)
+
+// yearTracker resolves year-less syslog timestamps for one file. Lines are
+// observed in file order and placed on a scale relative to the first line;
+// a month regression moves the scale into the next year. Once the whole file
+// has been read, the scale is anchored either at DefaultYear for the first
+// line or, walking back from the last line, at the reference time.
+type yearTracker struct {
+	opts    DateParseOptions
+	started bool
+	offset  int       // Years between the first line and the current one
+	prev    time.Time // Previous wall clock on the relative scale
+	last    time.Time // Last wall clock observed, as parsed
+}
+
+func newYearTracker(opts DateParseOptions) *yearTracker {
+	return &yearTracker{opts: opts}
+}
+
+// observe records the next year-less wall clock in the file. It returns the
+// year offset of the line and whether it goes backwards further than
+// backwardsTolerance from the line before it.
+func (y *yearTracker) observe(wall time.Time) (int, bool) {
+	cur := withYear(wall, wallClockYear+y.offset, time.UTC)
+	backwards := false
+
+	if y.started {
+		switch d := cur.Sub(y.prev); {
+		case d < -rolloverGap:
+			y.offset++
+		case d > rolloverGap:
+			// A late line from before the boundary, e.g. Dec 31 after Jan 1
+			y.offset--
+		}
+		cur = withYear(wall, wallClockYear+y.offset, time.UTC)
+		backwards = y.prev.Sub(cur) > backwardsTolerance
+	}
+
+	y.started = true
+	y.prev = cur
+	y.last = wall
+	return y.offset, backwards
+}
+
+// resolve turns an observed wall clock and its offset into a time in the
+// device zone.
+func (y *yearTracker) resolve(wall time.Time, offset int) time.Time {
+	return withYear(wall, y.firstYear()+offset, y.opts.location())
+}
+
+func (y *yearTracker) firstYear() int {
+	if y.opts.DefaultYear != 0 {
+		return y.opts.DefaultYear
+	}
+	return anchorYear(y.last, y.opts).Year() - y.offset
+}
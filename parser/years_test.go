@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestYearTracker(t *testing.T) {
+	wall := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(wallClockYear, month, day, hour, min, 0, 0, time.UTC)
+	}
+	type line struct {
+		wall      time.Time
+		want      string // RFC 3339, UTC
+		backwards bool
+	}
+	tests := []struct {
+		name  string
+		opts  DateParseOptions
+		lines []line
+	}{
+		{
+			name: "rollover from default year",
+			opts: DateParseOptions{DefaultYear: 2023, Location: time.UTC},
+			lines: []line{
+				{wall(time.December, 31, 23, 58), "2023-12-31T23:58:00Z", false},
+				{wall(time.January, 1, 0, 1), "2024-01-01T00:01:00Z", false},
+				{wall(time.January, 2, 9, 0), "2024-01-02T09:00:00Z", false},
+			},
+		},
+		{
+			name: "rollover anchored at the reference time",
+			opts: DateParseOptions{ReferenceTime: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Location: time.UTC},
+			lines: []line{
+				{wall(time.December, 30, 12, 0), "2024-12-30T12:00:00Z", false},
+				{wall(time.January, 1, 0, 0), "2025-01-01T00:00:00Z", false},
+			},
+		},
+		{
+			name: "late line across the boundary",
+			opts: DateParseOptions{DefaultYear: 2023, Location: time.UTC},
+			lines: []line{
+				{wall(time.December, 31, 23, 59), "2023-12-31T23:59:00Z", false},
+				{wall(time.January, 1, 0, 0), "2024-01-01T00:00:00Z", false},
+				{wall(time.December, 31, 23, 59), "2023-12-31T23:59:00Z", false},
+				{wall(time.January, 1, 0, 1), "2024-01-01T00:01:00Z", false},
+			},
+		},
+		{
+			name: "out of order beyond the tolerance",
+			opts: DateParseOptions{DefaultYear: 2023, Location: time.UTC},
+			lines: []line{
+				{wall(time.March, 5, 10, 0), "2023-03-05T10:00:00Z", false},
+				{wall(time.March, 5, 9, 0), "2023-03-05T09:00:00Z", false}, // Within the tolerance
+				{wall(time.March, 5, 6, 0), "2023-03-05T06:00:00Z", true},
+				{wall(time.February, 1, 0, 0), "2023-02-01T00:00:00Z", true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y := newYearTracker(tt.opts)
+			offsets := make([]int, len(tt.lines))
+			for i, l := range tt.lines {
+				var backwards bool
+				offsets[i], backwards = y.observe(l.wall)
+				if backwards != l.backwards {
+					t.Errorf("line %d: backwards = %v, want %v", i, backwards, l.backwards)
+				}
+			}
+			// Years are only known once the whole file has been observed.
+			for i, l := range tt.lines {
+				if got := y.resolve(l.wall, offsets[i]).UTC().Format(time.RFC3339); got != l.want {
+					t.Errorf("line %d: resolved to %s, want %s", i, got, l.want)
+				}
+			}
+		})
+	}
+}
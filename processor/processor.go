@@ -20,6 +20,7 @@ type Processor struct {
 	parser  *parser.Parser
 	db *repositories.PostgresDB
 	bigipConfig *parser.BigIPConfig
+	device *parser.DeviceInfo
 }
 
 type Config struct {
@@ -98,6 +99,9 @@ func (p *Processor) handleEvent(ctx context.Context, event interfaces.Event) err
 	if result.BigIPConfig != nil {
 		p.bigipConfig = result.BigIPConfig
 	}
+	if result.Device != nil {
+		p.device = result.Device
+	}
 
 	log.Printf("Processor: processed %s - found %d entries, indexed %d, errors: %d",
 		filename, result.EntriesFound, result.EntriesIndexed, len(result.Errors))
@@ -137,6 +141,10 @@ func (p *Processor) GetBigIPConfig() *parser.BigIPConfig {
 	return p.bigipConfig
 }
 
+func (p *Processor) GetDevice() *parser.DeviceInfo {
+	return p.device
+}
+
 func (p *Processor) Close() error {
 	var errs []error
 
@@ -0,0 +1,212 @@
+package rules
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+// Sample lines kept per match.
+const sampleLines = 3
+
+// Match is one finding of a rule: a configuration object it selected, or a
+// group of log lines that reached its threshold.
+type Match struct {
+	Rule    *Rule
+	Group   string                 // Object, file or host the lines were counted for
+	Count   int                    // Lines in the window; 0 for config-only rules
+	First   time.Time              // Time of the first line in the window
+	Last    time.Time              // Time of the last line in the window
+	Objects []interfaces.ObjectRef // Objects selected or named by the lines
+	Samples []interfaces.LogEntry  // Earliest lines in the window
+}
+
+// Evaluate runs every rule against the log entries and the configuration,
+// which may be nil.
+func (s *RuleSet) Evaluate(entries []interfaces.LogEntry, config *parser.BigIPConfig) []Match {
+	matches := []Match{}
+	for _, rule := range s.rules {
+		matches = append(matches, rule.evaluate(entries, config)...)
+	}
+	return matches
+}
+
+func (r *Rule) evaluate(entries []interfaces.LogEntry, config *parser.BigIPConfig) []Match {
+	var matches []Match
+	selected := make(map[interfaces.ObjectRef]bool)
+	if r.Config != nil {
+		for _, o := range objects(config, r.Config.Kind) {
+			if !r.Config.matches(o) {
+				continue
+			}
+			selected[o.ref] = true
+			if r.Log == nil {
+				matches = append(matches, Match{Rule: r, Group: refLabel(o.ref), Objects: []interfaces.ObjectRef{o.ref}})
+			}
+		}
+		if r.Log == nil || len(selected) == 0 {
+			return matches
+		}
+	}
+
+	// Lines are counted per group; with a config match only the lines naming
+	// a selected object count.
+	type line struct {
+		entry interfaces.LogEntry
+		refs  []interfaces.ObjectRef
+	}
+	groups := make(map[string][]line)
+	for _, entry := range entries {
+		if !r.matchesEntry(entry) {
+			continue
+		}
+		refs := entry.Objects
+		if r.Config != nil {
+			refs = nil
+			for _, ref := range entry.Objects {
+				if selected[ref] {
+					refs = append(refs, ref)
+				}
+			}
+			if len(refs) == 0 {
+				continue
+			}
+		}
+
+		switch r.Threshold.GroupBy {
+		case "object":
+			for _, ref := range refs {
+				key := refLabel(ref)
+				groups[key] = append(groups[key], line{entry, []interfaces.ObjectRef{ref}})
+			}
+		case "file":
+			key := logPath(entry.Path)
+			groups[key] = append(groups[key], line{entry, refs})
+		case "host":
+			key := entry.Host
+			if key == "" {
+				key = entry.Hostname
+			}
+			groups[key] = append(groups[key], line{entry, refs})
+		default:
+			groups[""] = append(groups[""], line{entry, refs})
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lines := groups[key]
+		if r.window > 0 {
+			lines = slices.DeleteFunc(lines, func(l line) bool { return l.entry.Timestamp.IsZero() })
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].entry.Timestamp.Before(lines[j].entry.Timestamp)
+		})
+
+		// The busiest window; without one, all lines of the group.
+		start, end := 0, len(lines)
+		if r.window > 0 {
+			best := 0
+			for i, j := 0, 0; i < len(lines); i++ {
+				for lines[i].entry.Timestamp.Sub(lines[j].entry.Timestamp) >= r.window {
+					j++
+				}
+				if i-j+1 > best {
+					best, start, end = i-j+1, j, i+1
+				}
+			}
+		}
+		window := lines[start:end]
+		if len(window) == 0 || len(window) < r.Threshold.Count {
+			continue
+		}
+
+		match := Match{
+			Rule:    r,
+			Group:   key,
+			Count:   len(window),
+			First:   window[0].entry.Timestamp,
+			Last:    window[len(window)-1].entry.Timestamp,
+			Objects: []interfaces.ObjectRef{},
+		}
+		for _, l := range window {
+			for _, ref := range l.refs {
+				if !slices.Contains(match.Objects, ref) {
+					match.Objects = append(match.Objects, ref)
+				}
+			}
+			if len(match.Samples) < sampleLines {
+				match.Samples = append(match.Samples, l.entry)
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// matchesEntry reports whether the entry meets every condition of the log
+// match.
+func (r *Rule) matchesEntry(entry interfaces.LogEntry) bool {
+	m := r.Log
+	if len(m.MessageIDs) > 0 && !slices.Contains(m.MessageIDs, normalizeID(entry.MessageID)) {
+		return false
+	}
+	if len(m.Severity) > 0 && !slices.Contains(m.Severity, entry.Status) {
+		return false
+	}
+	if len(m.Files) > 0 {
+		rel := logPath(entry.Path)
+		found := false
+		for _, pattern := range m.Files {
+			target := filepath.Base(rel)
+			if strings.Contains(pattern, "/") {
+				target = rel
+			}
+			if ok, _ := filepath.Match(pattern, target); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.regex != nil {
+		message := entry.Message
+		if message == "" {
+			message = entry.Line
+		}
+		if !r.regex.MatchString(message) {
+			return false
+		}
+	}
+	return true
+}
+
+// refLabel names an object in a match: its path, or pool/member for members.
+func refLabel(ref interfaces.ObjectRef) string {
+	if ref.Kind == parser.ObjectMember {
+		return ref.Pool + "/" + ref.Name
+	}
+	return ref.Path
+}
+
+// logPath is the path of a log file below var/log, e.g. ltm or
+// journal/system.journal.
+func logPath(path string) string {
+	path = filepath.ToSlash(path)
+	if i := strings.LastIndex(path, "/var/log/"); i >= 0 {
+		return path[i+len("/var/log/"):]
+	}
+	return filepath.Base(path)
+}
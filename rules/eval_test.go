@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+func TestEvaluate(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	poolA := interfaces.ObjectRef{Kind: parser.ObjectPool, Name: "a", Path: "/Common/a"}
+	poolB := interfaces.ObjectRef{Kind: parser.ObjectPool, Name: "b", Path: "/Common/b"}
+	config := &parser.BigIPConfig{ByPath: parser.ObjectsByPath{Pools: map[string]*parser.PoolConfig{
+		"/Common/a": {Name: "a", Partition: "Common", Path: "/Common/a"},
+		"/Common/b": {Name: "b", Partition: "Common", Path: "/Common/b", Monitor: "http", Monitors: []string{"http"}},
+	}}}
+
+	// entry is a matching line offset from base; a negative offset means no
+	// timestamp.
+	entry := func(offset time.Duration, file, host string, refs ...interfaces.ObjectRef) interfaces.LogEntry {
+		e := interfaces.LogEntry{Path: "/qkview/var/log/" + file, Line: "down", Message: "down", Hostname: "bigip1", Host: host, Objects: refs}
+		if offset >= 0 {
+			e.Timestamp = base.Add(offset)
+		}
+		return e
+	}
+	noConfig := []interfaces.LogEntry{
+		entry(0, "ltm", "", poolA),
+		entry(5*time.Minute, "ltm", "", poolA),
+		entry(20*time.Minute, "ltm", "", poolB),
+	}
+	unmonitored := &ConfigMatch{Kind: parser.ObjectPool, Where: []Condition{{Property: "monitor", Op: "empty"}}}
+
+	tests := []struct {
+		name      string
+		config    *ConfigMatch
+		threshold Threshold
+		entries   []interfaces.LogEntry
+		want      []string // Group, count, first and last line of each match
+	}{
+		{
+			name:    "every line by default",
+			entries: noConfig,
+			want:    []string{" 3 10:00-10:20"},
+		},
+		{
+			name:      "count just reached",
+			threshold: Threshold{Count: 3},
+			entries:   noConfig,
+			want:      []string{" 3 10:00-10:20"},
+		},
+		{
+			name:      "count not reached",
+			threshold: Threshold{Count: 4},
+			entries:   noConfig,
+		},
+		{
+			name:      "busiest window",
+			threshold: Threshold{Count: 2, Window: "10m"},
+			entries: []interfaces.LogEntry{
+				entry(0, "ltm", ""),
+				entry(30*time.Minute, "ltm", ""),
+				entry(35*time.Minute, "ltm", ""),
+				entry(39*time.Minute, "ltm", ""),
+				entry(50*time.Minute, "ltm", ""),
+			},
+			want: []string{" 3 10:30-10:39"},
+		},
+		{
+			name:      "window excludes its end",
+			threshold: Threshold{Count: 2, Window: "10m"},
+			entries:   []interfaces.LogEntry{entry(0, "ltm", ""), entry(10*time.Minute, "ltm", "")},
+		},
+		{
+			name:      "window ignores lines without time",
+			threshold: Threshold{Count: 2, Window: "10m"},
+			entries:   []interfaces.LogEntry{entry(-1, "ltm", ""), entry(0, "ltm", ""), entry(-1, "ltm", "")},
+		},
+		{
+			name:      "out of order lines",
+			threshold: Threshold{Count: 3, Window: "5m"},
+			entries:   []interfaces.LogEntry{entry(4*time.Minute, "ltm", ""), entry(0, "ltm", ""), entry(2*time.Minute, "ltm", "")},
+			want:      []string{" 3 10:00-10:04"},
+		},
+		{
+			name:      "group by object",
+			threshold: Threshold{Count: 2, GroupBy: "object"},
+			entries:   append(noConfig, entry(30*time.Minute, "ltm", "", poolA, poolB)),
+			want:      []string{"/Common/a 3 10:00-10:30", "/Common/b 2 10:20-10:30"},
+		},
+		{
+			name:      "group by file",
+			threshold: Threshold{GroupBy: "file"},
+			entries:   []interfaces.LogEntry{entry(0, "ltm", ""), entry(time.Minute, "ltm.1", ""), entry(2*time.Minute, "ltm", "")},
+			want:      []string{"ltm 2 10:00-10:02", "ltm.1 1 10:01-10:01"},
+		},
+		{
+			name:      "group by host falls back to the device",
+			threshold: Threshold{GroupBy: "host"},
+			entries:   []interfaces.LogEntry{entry(0, "ltm", "bigip2"), entry(time.Minute, "ltm", "")},
+			want:      []string{"bigip1 1 10:01-10:01", "bigip2 1 10:00-10:00"},
+		},
+		{
+			name:    "only lines naming selected objects",
+			config:  unmonitored,
+			entries: append(noConfig, entry(30*time.Minute, "ltm", "")),
+			want:    []string{" 2 10:00-10:05"},
+		},
+		{
+			name:      "selected objects below the count",
+			config:    unmonitored,
+			threshold: Threshold{Count: 3},
+			entries:   noConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{ID: "test", Title: "Test", Severity: "warning", Log: &LogMatch{Regex: "down"}, Config: tt.config, Threshold: tt.threshold}
+			if err := rule.compile(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range (&RuleSet{rules: []*Rule{rule}}).Evaluate(tt.entries, config) {
+				got = append(got, fmt.Sprintf("%s %d %s-%s", m.Group, m.Count, m.First.Format("15:04"), m.Last.Format("15:04")))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateConfig(t *testing.T) {
+	config := &parser.BigIPConfig{ByPath: parser.ObjectsByPath{Pools: map[string]*parser.PoolConfig{
+		"/Tenant/app": {Name: "app", Partition: "Tenant", Path: "/Tenant/app"},
+		"/Common/app": {Name: "app", Partition: "Common", Path: "/Common/app", Monitor: "tcp", Monitors: []string{"tcp"}},
+		"/Common/web": {Name: "web", Partition: "Common", Path: "/Common/web"},
+	}}}
+	rule := &Rule{ID: "test", Title: "Test", Severity: "warning", Config: &ConfigMatch{
+		Kind:  parser.ObjectPool,
+		Where: []Condition{{Property: "monitor", Op: "empty"}},
+	}}
+	if err := rule.compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range (&RuleSet{rules: []*Rule{rule}}).Evaluate(nil, config) {
+		if m.Count != 0 || len(m.Objects) != 1 || m.Objects[0].Path != m.Group {
+			t.Errorf("match %+v, want one object and no lines", m)
+		}
+		got = append(got, m.Group)
+	}
+	if want := []string{"/Common/web", "/Tenant/app"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluate() = %q, want %q", got, want)
+	}
+}
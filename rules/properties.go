@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"sort"
+	"strconv"
+
+	"goqkview/interfaces"
+	"goqkview/parser"
+)
+
+// properties lists what conditions can test on each kind of object. Lists
+// such as profiles hold one value per element; booleans are "true" or
+// "false".
+var properties = map[string]map[string]bool{
+	parser.ObjectVirtual: set("name", "path", "partition", "pool", "destination", "disabled", "ipProtocol", "profiles", "rules"),
+	parser.ObjectPool:    set("name", "path", "partition", "monitor", "monitors", "members", "activeMembers"),
+	parser.ObjectMember:  set("name", "path", "pool", "address", "disabled", "down", "status", "available"),
+	parser.ObjectNode:    set("name", "path", "partition", "address", "monitor", "monitors", "ratio", "connectionLimit", "disabled", "down"),
+	parser.ObjectRule:    set("name", "path", "partition"),
+	parser.ObjectMonitor: set("name", "path", "partition", "type", "defaultsFrom"),
+	parser.ObjectProfile: set("name", "path", "partition", "type", "defaultsFrom"),
+}
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
+
+// object is a configuration object with the values of its properties.
+type object struct {
+	ref   interfaces.ObjectRef
+	props map[string][]string
+}
+
+// objects returns the objects of kind in config, ordered by path.
+func objects(config *parser.BigIPConfig, kind string) []object {
+	var result []object
+	if config == nil {
+		return result
+	}
+
+	add := func(ref interfaces.ObjectRef, props map[string][]string) {
+		props["name"] = []string{ref.Name}
+		props["path"] = []string{ref.Path}
+		result = append(result, object{ref: ref, props: props})
+	}
+	switch kind {
+	case parser.ObjectVirtual:
+		for _, vs := range config.ByPath.VirtualServers {
+			add(interfaces.ObjectRef{Kind: kind, Name: vs.Name, Path: vs.Path}, map[string][]string{
+				"partition":   {vs.Partition},
+				"pool":        {vs.Pool},
+				"destination": {vs.Destination},
+				"disabled":    {strconv.FormatBool(vs.Disabled)},
+				"ipProtocol":  {vs.IPProtocol},
+				"profiles":    vs.Profiles,
+				"rules":       vs.Rules,
+			})
+		}
+	case parser.ObjectPool:
+		for _, pool := range config.ByPath.Pools {
+			add(interfaces.ObjectRef{Kind: kind, Name: pool.Name, Path: pool.Path}, map[string][]string{
+				"partition":     {pool.Partition},
+				"monitor":       {pool.Monitor},
+				"monitors":      pool.Monitors,
+				"members":       {strconv.Itoa(pool.GetTotalMembers())},
+				"activeMembers": {strconv.Itoa(pool.GetActiveMembers())},
+			})
+		}
+	case parser.ObjectMember:
+		for _, pool := range config.ByPath.Pools {
+			for _, m := range pool.Members {
+				add(interfaces.ObjectRef{Kind: kind, Name: m.Name, Path: m.Path, Pool: pool.Path}, map[string][]string{
+					"pool":      {pool.Name},
+					"address":   {m.Address},
+					"disabled":  {strconv.FormatBool(m.Disabled)},
+					"down":      {strconv.FormatBool(m.Down)},
+					"status":    {string(m.Status)},
+					"available": {strconv.FormatBool(m.Available())},
+				})
+			}
+		}
+	case parser.ObjectNode:
+		for _, node := range config.ByPath.Nodes {
+			add(interfaces.ObjectRef{Kind: kind, Name: node.Name, Path: node.Path}, map[string][]string{
+				"partition":       {node.Partition},
+				"address":         {node.Address},
+				"monitor":         {node.Monitor},
+				"monitors":        node.Monitors,
+				"ratio":           {strconv.Itoa(node.Ratio)},
+				"connectionLimit": {strconv.Itoa(node.ConnectionLimit)},
+				"disabled":        {strconv.FormatBool(node.Disabled)},
+				"down":            {strconv.FormatBool(node.Down)},
+			})
+		}
+	case parser.ObjectRule:
+		for _, rule := range config.ByPath.Rules {
+			add(interfaces.ObjectRef{Kind: kind, Name: rule.Name, Path: rule.Path}, map[string][]string{
+				"partition": {rule.Partition},
+			})
+		}
+	case parser.ObjectMonitor:
+		for _, monitor := range config.ByPath.Monitors {
+			add(interfaces.ObjectRef{Kind: kind, Name: monitor.Name, Path: monitor.Path}, map[string][]string{
+				"partition":    {monitor.Partition},
+				"type":         {monitor.Type},
+				"defaultsFrom": {monitor.DefaultsFrom},
+			})
+		}
+	case parser.ObjectProfile:
+		for _, profile := range config.ByPath.Profiles {
+			add(interfaces.ObjectRef{Kind: kind, Name: profile.Name, Path: profile.Path}, map[string][]string{
+				"partition":    {profile.Partition},
+				"type":         {profile.Type},
+				"defaultsFrom": {profile.DefaultsFrom},
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].ref, result[j].ref
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Pool < b.Pool
+	})
+	return result
+}
+
+// holds reports whether the object meets the condition.
+func (c Condition) holds(o object) bool {
+	var values []string
+	for _, v := range o.props[c.Property] {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+
+	switch c.Op {
+	case "empty":
+		return len(values) == 0
+	case "notEmpty":
+		return len(values) > 0
+	case "notEquals":
+		for _, v := range values {
+			if v == c.Value {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		switch c.Op {
+		case "equals":
+			if v == c.Value {
+				return true
+			}
+		case "matches":
+			if c.regex.MatchString(v) {
+				return true
+			}
+		case "lt", "gt":
+			number, err := strconv.ParseFloat(v, 64)
+			if err == nil && ((c.Op == "lt" && number < c.number) || (c.Op == "gt" && number > c.number)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether the object meets every condition of m.
+func (m *ConfigMatch) matches(o object) bool {
+	for _, c := range m.Where {
+		if !c.holds(o) {
+			return false
+		}
+	}
+	return true
+}
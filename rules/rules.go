@@ -0,0 +1,346 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule declares a finding: log lines to look for, configuration objects to
+// look at, or log lines naming such objects, and how many lines in what time
+// it takes.
+type Rule struct {
+	ID          string   `yaml:"id"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Remediation string   `yaml:"remediation"`
+	Severity    string   `yaml:"severity"` // critical, warning, info
+	Tags        []string `yaml:"tags"`
+
+	Log       *LogMatch    `yaml:"log"`
+	Config    *ConfigMatch `yaml:"config"`
+	Threshold Threshold    `yaml:"threshold"`
+
+	File string `yaml:"-"` // File the rule was loaded from
+
+	regex  *regexp.Regexp
+	window time.Duration
+}
+
+// LogMatch selects log entries; every field that is set must match.
+type LogMatch struct {
+	MessageIDs []string `yaml:"messageId"` // e.g. 01010028
+	Regex      string   `yaml:"regex"`     // Against the message, else the whole line
+	Severity   []string `yaml:"severity"`  // Entry status, e.g. error, warning
+	Files      []string `yaml:"file"`      // Globs on the path below var/log, e.g. ltm*
+}
+
+// ConfigMatch selects configuration objects of one kind whose properties
+// meet every condition.
+type ConfigMatch struct {
+	Kind  string      `yaml:"kind"` // virtual, pool, member, node, rule, monitor, profile
+	Where []Condition `yaml:"where"`
+}
+
+type Condition struct {
+	Property string `yaml:"property"`
+	Op       string `yaml:"op"` // equals, notEquals, matches, empty, notEmpty, lt, gt
+	Value    string `yaml:"value"`
+
+	regex  *regexp.Regexp
+	number float64
+}
+
+// Threshold says how many matching log lines make a finding.
+type Threshold struct {
+	Count   int    `yaml:"count"`   // Default 1
+	Window  string `yaml:"window"`  // Within this long, e.g. 10m; empty means anywhere in the logs
+	GroupBy string `yaml:"groupBy"` // object, file or host: count each value separately
+}
+
+// RuleSet is the rules loaded from a directory, in file and then
+// declaration order.
+type RuleSet struct {
+	rules []*Rule
+}
+
+type ruleFile struct {
+	Rules []yaml.Node `yaml:"rules"`
+}
+
+// Load reads every *.yaml and *.yml file in dir. An empty dir gives an empty
+// set; the first invalid rule is an error.
+func Load(dir string) (*RuleSet, error) {
+	if dir == "" {
+		return &RuleSet{}, nil
+	}
+	set, problems, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("rules: %w", problems[0])
+	}
+	return set, nil
+}
+
+// Lint loads dir like Load but reports every problem found, and the number
+// of valid rules.
+func Lint(dir string) (int, []error, error) {
+	set, problems, err := load(dir)
+	if err != nil {
+		return 0, nil, err
+	}
+	return set.Len(), problems, nil
+}
+
+func load(dir string) (*RuleSet, []error, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rules: failed to read %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, f := range files {
+		if ext := filepath.Ext(f.Name()); !f.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	set := &RuleSet{}
+	var problems []error
+	seen := make(map[string]string)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rules: failed to read %s: %w", path, err)
+		}
+
+		var f ruleFile
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			problems = append(problems, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+
+		// Rules are decoded one by one so that one bad rule does not hide
+		// the problems of the others.
+		for i := range f.Rules {
+			node := &f.Rules[i]
+			rule := &Rule{}
+			if err := node.Decode(rule); err != nil {
+				problems = append(problems, fmt.Errorf("%s:%d: rule %d: %w", path, node.Line, i+1, err))
+				continue
+			}
+			if unknown := unknownFields(node, reflect.TypeOf(rule)); len(unknown) > 0 {
+				for _, field := range unknown {
+					problems = append(problems, fmt.Errorf("%s:%d: rule %s: unknown field %q", path, field.Line, rule.ID, field.Value))
+				}
+				continue
+			}
+			rule.File = path
+			if err := rule.compile(); err != nil {
+				problems = append(problems, fmt.Errorf("%s:%d: %w", path, node.Line, err))
+				continue
+			}
+			if other, ok := seen[rule.ID]; ok {
+				problems = append(problems, fmt.Errorf("%s:%d: rule %s is already defined in %s", path, node.Line, rule.ID, other))
+				continue
+			}
+			seen[rule.ID] = path
+			set.rules = append(set.rules, rule)
+		}
+	}
+	return set, problems, nil
+}
+
+func (s *RuleSet) Rules() []*Rule {
+	return s.rules
+}
+
+func (s *RuleSet) Len() int {
+	return len(s.rules)
+}
+
+// compile validates the rule and prepares its patterns and window.
+func (r *Rule) compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("rule without an id")
+	}
+	if r.Title == "" {
+		return fmt.Errorf("rule %s has no title", r.ID)
+	}
+	switch r.Severity {
+	case "critical", "warning", "info":
+	default:
+		return fmt.Errorf("rule %s: invalid severity %q (want critical, warning or info)", r.ID, r.Severity)
+	}
+	if r.Log == nil && r.Config == nil {
+		return fmt.Errorf("rule %s matches nothing: set log, config or both", r.ID)
+	}
+
+	if r.Log != nil {
+		if err := r.compileLog(); err != nil {
+			return err
+		}
+	}
+	if r.Config != nil {
+		if err := r.compileConfig(); err != nil {
+			return err
+		}
+	}
+
+	t := &r.Threshold
+	if r.Log == nil && (t.Count != 0 || t.Window != "" || t.GroupBy != "") {
+		return fmt.Errorf("rule %s: threshold needs a log match", r.ID)
+	}
+	if t.Count < 0 {
+		return fmt.Errorf("rule %s: invalid threshold count %d", r.ID, t.Count)
+	}
+	if t.Count == 0 {
+		t.Count = 1
+	}
+	if t.Window != "" {
+		window, err := time.ParseDuration(t.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("rule %s: invalid window %q (want a duration such as 10m or 1h)", r.ID, t.Window)
+		}
+		r.window = window
+	}
+	switch t.GroupBy {
+	case "", "object", "file", "host":
+	default:
+		return fmt.Errorf("rule %s: invalid groupBy %q (want object, file or host)", r.ID, t.GroupBy)
+	}
+	return nil
+}
+
+func (r *Rule) compileLog() error {
+	m := r.Log
+	if len(m.MessageIDs) == 0 && m.Regex == "" && len(m.Severity) == 0 && len(m.Files) == 0 {
+		return fmt.Errorf("rule %s: log match has no conditions", r.ID)
+	}
+	for i, id := range m.MessageIDs {
+		m.MessageIDs[i] = normalizeID(id)
+	}
+	if m.Regex != "" {
+		regex, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid regex: %w", r.ID, err)
+		}
+		r.regex = regex
+	}
+	for i, severity := range m.Severity {
+		m.Severity[i] = strings.ToUpper(severity)
+		if status, ok := syslogSeverities[strings.ToLower(severity)]; ok {
+			m.Severity[i] = status
+		}
+		switch m.Severity[i] {
+		case "EMERGENCY", "ALERT", "CRITICAL", "ERROR", "SEVERE", "WARNING", "NOTICE", "INFO", "DEBUG":
+		default:
+			return fmt.Errorf("rule %s: unknown log severity %q", r.ID, severity)
+		}
+	}
+	for _, pattern := range m.Files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("rule %s: invalid file pattern %q: %w", r.ID, pattern, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) compileConfig() error {
+	m := r.Config
+	known, ok := properties[m.Kind]
+	if !ok {
+		return fmt.Errorf("rule %s: unknown config kind %q", r.ID, m.Kind)
+	}
+	for i := range m.Where {
+		c := &m.Where[i]
+		if !known[c.Property] {
+			return fmt.Errorf("rule %s: %s has no property %q", r.ID, m.Kind, c.Property)
+		}
+		switch c.Op {
+		case "equals", "notEquals", "empty", "notEmpty":
+		case "matches":
+			regex, err := regexp.Compile(c.Value)
+			if err != nil {
+				return fmt.Errorf("rule %s: %s: invalid regex: %w", r.ID, c.Property, err)
+			}
+			c.regex = regex
+		case "lt", "gt":
+			number, err := strconv.ParseFloat(c.Value, 64)
+			if err != nil {
+				return fmt.Errorf("rule %s: %s: %s needs a number, got %q", r.ID, c.Property, c.Op, c.Value)
+			}
+			c.number = number
+		default:
+			return fmt.Errorf("rule %s: %s: unknown op %q", r.ID, c.Property, c.Op)
+		}
+	}
+	return nil
+}
+
+// syslogSeverities maps syslog keywords to entry statuses, so rules may say
+// err as well as error.
+var syslogSeverities = map[string]string{
+	"emerg": "EMERGENCY",
+	"crit":  "CRITICAL",
+	"err":   "ERROR",
+	"warn":  "WARNING",
+}
+
+// unknownFields returns the mapping keys below node that the struct fields
+// of t do not declare.
+func unknownFields(node *yaml.Node, t reflect.Type) []*yaml.Node {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var unknown []*yaml.Node
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if field, ok := fields[key.Value]; ok {
+				unknown = append(unknown, unknownFields(value, field)...)
+			} else {
+				unknown = append(unknown, key)
+			}
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			unknown = append(unknown, unknownFields(item, t.Elem())...)
+		}
+	}
+	return unknown
+}
+
+// normalizeID accepts "01010028" as well as "01010028:3:".
+func normalizeID(id string) string {
+	id = strings.TrimSpace(id)
+	if i := strings.Index(id, ":"); i != -1 {
+		id = id[:i]
+	}
+	return strings.ToUpper(id)
+}
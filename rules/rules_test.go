@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const validRule = `rules:
+  - id: pool-down
+    title: Pool down
+    severity: critical
+    log:
+      messageId: ["01010028:3:"]
+      severity: [err]
+    threshold:
+      count: 2
+      window: 10m
+      groupBy: object
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		rules int
+		err   string // Substring of the error; empty for none
+	}{
+		{"valid", map[string]string{"a.yaml": validRule}, 1, ""},
+		{"other files ignored", map[string]string{"a.yml": validRule, "notes.txt": "rules: [", "b.json": "{"}, 1, ""},
+		{"empty file", map[string]string{"a.yaml": ""}, 0, ""},
+		{"syntax error", map[string]string{"a.yaml": "rules:\n  - id: [x\n"}, 0, "a.yaml"},
+		{"unknown top-level key", map[string]string{"a.yaml": "rule:\n  - id: x\n"}, 0, "field rule not found"},
+		{"unknown rule field", map[string]string{"a.yaml": strings.Replace(validRule, "severity: critical", "severty: critical", 1)}, 0, `unknown field "severty"`},
+		{"unknown nested field", map[string]string{"a.yaml": strings.Replace(validRule, "groupBy: object", "group: object", 1)}, 0, `unknown field "group"`},
+		{"duplicate id", map[string]string{"a.yaml": validRule, "b.yaml": validRule}, 0, "pool-down is already defined in"},
+		{"no id", map[string]string{"a.yaml": strings.Replace(validRule, "id: pool-down", "id: ''", 1)}, 0, "rule without an id"},
+		{"invalid severity", map[string]string{"a.yaml": strings.Replace(validRule, "severity: critical", "severity: high", 1)}, 0, `invalid severity "high"`},
+		{"unknown log severity", map[string]string{"a.yaml": strings.Replace(validRule, "[err]", "[fatal]", 1)}, 0, `unknown log severity "fatal"`},
+		{"invalid window", map[string]string{"a.yaml": strings.Replace(validRule, "10m", "10x", 1)}, 0, `invalid window "10x"`},
+		{"invalid groupBy", map[string]string{"a.yaml": strings.Replace(validRule, "groupBy: object", "groupBy: pool", 1)}, 0, `invalid groupBy "pool"`},
+		{"negative count", map[string]string{"a.yaml": strings.Replace(validRule, "count: 2", "count: -1", 1)}, 0, "invalid threshold count -1"},
+		{"matches nothing", map[string]string{"a.yaml": "rules:\n  - id: x\n    title: X\n    severity: info\n"}, 0, "matches nothing"},
+		{"invalid regex", map[string]string{"a.yaml": "rules:\n  - id: x\n    title: X\n    severity: info\n    log:\n      regex: '('\n"}, 0, "invalid regex"},
+		{"threshold without log", map[string]string{"a.yaml": "rules:\n  - id: x\n    title: X\n    severity: info\n    config:\n      kind: pool\n    threshold:\n      count: 2\n"}, 0, "threshold needs a log match"},
+		{"unknown kind", map[string]string{"a.yaml": "rules:\n  - id: x\n    title: X\n    severity: info\n    config:\n      kind: vlan\n"}, 0, `unknown config kind "vlan"`},
+		{"unknown property", map[string]string{"a.yaml": "rules:\n  - id: x\n    title: X\n    severity: info\n    config:\n      kind: pool\n      where:\n        - property: ratio\n          op: equals\n"}, 0, `pool has no property "ratio"`},
+		{"non-numeric lt", map[string]string{"a.yaml": "rules:\n  - id: x\n    title: X\n    severity: info\n    config:\n      kind: node\n      where:\n        - property: ratio\n          op: lt\n          value: low\n"}, 0, `lt needs a number, got "low"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Load(writeRules(t, tt.files))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if set.Len() != tt.rules {
+				t.Errorf("Load() = %d rules, want %d", set.Len(), tt.rules)
+			}
+		})
+	}
+}
+
+func TestLoadNormalizes(t *testing.T) {
+	set, err := Load(writeRules(t, map[string]string{"a.yaml": validRule}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := set.Rules()[0]
+	if got := rule.Log.MessageIDs; len(got) != 1 || got[0] != "01010028" {
+		t.Errorf("MessageIDs = %q, want [01010028]", got)
+	}
+	if got := rule.Log.Severity; len(got) != 1 || got[0] != "ERROR" {
+		t.Errorf("Severity = %q, want [ERROR]", got)
+	}
+	if !strings.HasSuffix(rule.File, "a.yaml") {
+		t.Errorf("File = %q, want the rule file", rule.File)
+	}
+}
+
+func TestLoadExamples(t *testing.T) {
+	set, err := Load("examples")
+	if err != nil {
+		t.Fatalf("Load(examples): %v", err)
+	}
+	if set.Len() == 0 {
+		t.Error("Load(examples) found no rules")
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir := writeRules(t, map[string]string{
+		"a.yaml": validRule + `  - id: bad-field
+    title: Bad field
+    severity: info
+    log:
+      regex: x
+      messageIds: ["01010028"]
+  - id: second
+    title: Second
+    severity: info
+    config:
+      kind: virtual
+`,
+		"b.yaml": validRule,
+		"c.yaml": "rules: {",
+	})
+	valid, problems, err := Lint(dir)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if valid != 2 {
+		t.Errorf("Lint() = %d valid rules, want 2", valid)
+	}
+	want := []string{
+		`a.yaml:17: rule bad-field: unknown field "messageIds"`,
+		"b.yaml:2: rule pool-down is already defined in",
+		"c.yaml",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Lint() problems = %v, want %d", problems, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(problems[i].Error(), w) {
+			t.Errorf("problem %d = %v, want one containing %q", i, problems[i], w)
+		}
+	}
+
+	if _, _, err := Lint(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Lint() of a missing directory succeeded")
+	}
+}